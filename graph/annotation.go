@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// annotationPagePrefix starts the names of the pages Logseq's PDF annotator creates.
+const annotationPagePrefix = "hls__"
+
+// Annotation is a PDF highlight made with Logseq's annotator.
+type Annotation struct {
+	Page  int    `json:"page"`            // PDF page number, from hl-page
+	Color string `json:"color,omitempty"` // From hl-color
+	Area  bool   `json:"area,omitempty"`  // True for area highlights, which are images
+	Stamp string `json:"stamp,omitempty"` // From hl-stamp, naming an area highlight's image
+}
+
+// Annotation returns the PDF highlight a block records, if it has ls-type:: annotation.
+func (b *Block) Annotation() *Annotation {
+	lsType, ok := b.Properties.Get("ls-type")
+	if !ok || lsType.Value != "annotation" {
+		return nil
+	}
+
+	annotation := Annotation{}
+
+	if pageProp, ok := b.Properties.Get("hl-page"); ok {
+		annotation.Page, _ = strconv.Atoi(strings.TrimSpace(pageProp.Value))
+	}
+
+	if colorProp, ok := b.Properties.Get("hl-color"); ok {
+		annotation.Color = colorProp.Value
+	}
+
+	if typeProp, ok := b.Properties.Get("hl-type"); ok {
+		annotation.Area = typeProp.Value == "area"
+	}
+
+	if stampProp, ok := b.Properties.Get("hl-stamp"); ok {
+		annotation.Stamp = stampProp.Value
+	}
+
+	return &annotation
+}
+
+// AnnotationImagePath returns where Logseq keeps the image of an area highlight,
+// relative to the assets folder: storages/<pdf name>/<page>_<block id>_<stamp>.png.
+func AnnotationImagePath(pdfPath string, block *Block, annotation Annotation) string {
+	pdfName := strings.TrimSuffix(path.Base(pdfPath), path.Ext(pdfPath))
+
+	return path.Join("storages", pdfName, strconv.Itoa(annotation.Page)+"_"+block.ID+"_"+annotation.Stamp+".png")
+}
+
+// IsAnnotationPage returns true if the page holds PDF highlights.
+func (p *Page) IsAnnotationPage() bool {
+	return strings.HasPrefix(strings.ToLower(p.Name), annotationPagePrefix)
+}
+
+// AnnotatedAssetPath returns the path in the assets folder of the PDF an annotation page
+// highlights, from its file-path:: property.
+func (p *Page) AnnotatedAssetPath() (string, bool) {
+	if !p.IsAnnotationPage() || p.Root == nil {
+		return "", false
+	}
+
+	fileProp, ok := p.Root.Properties.Get("file-path")
+	if !ok {
+		return "", false
+	}
+
+	assetPath := fileProp.Value
+	if index := strings.Index(assetPath, "assets/"); index >= 0 {
+		assetPath = assetPath[index+len("assets/"):]
+	}
+
+	return assetPath, assetPath != ""
+}
+
+// AnnotationAssetPaths returns the paths in the assets folder of the PDF an annotation page
+// highlights, and of the images of its public area highlights.
+func (p *Page) AnnotationAssetPaths() []string {
+	pdfPath, ok := p.AnnotatedAssetPath()
+	if !ok {
+		return []string{}
+	}
+
+	paths := []string{pdfPath}
+
+	for _, block := range p.AllBlocks {
+		if annotation := block.Annotation(); annotation != nil && annotation.Area && block.IsPublic() {
+			paths = append(paths, AnnotationImagePath(pdfPath, block, *annotation))
+		}
+	}
+
+	return paths
+}
+
+// AnnotatedAsset returns the PDF asset an annotation page highlights.
+func (g *Graph) AnnotatedAsset(page *Page) (Asset, bool) {
+	assetPath, ok := page.AnnotatedAssetPath()
+	if !ok {
+		return Asset{}, false
+	}
+
+	return g.FindAsset(assetPath)
+}
@@ -0,0 +1,102 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+)
+
+func TestBlock_Annotation(t *testing.T) {
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlock(&page, []string{
+		"[:span]",
+		"ls-type:: annotation",
+		"hl-page:: 14",
+		"hl-color:: red",
+		"hl-type:: area",
+		"hl-stamp:: 1685000000000",
+		"id:: 6650bf5e-0000-4000-8000-000000000002",
+	}, 1)
+	require.NoError(t, err)
+
+	annotation := block.Annotation()
+
+	require.NotNil(t, annotation)
+	assert.Equal(t, graph.Annotation{Page: 14, Color: "red", Area: true, Stamp: "1685000000000"}, *annotation)
+	assert.Equal(t,
+		"storages/book_1700/14_6650bf5e-0000-4000-8000-000000000002_1685000000000.png",
+		graph.AnnotationImagePath("book_1700.pdf", block, *annotation),
+	)
+}
+
+func TestBlock_Annotation_NotAnnotation(t *testing.T) {
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlock(&page, []string{"hl-page:: 3"}, 1)
+	require.NoError(t, err)
+
+	assert.Nil(t, block.Annotation())
+}
+
+func TestGraph_AnnotatedAsset(t *testing.T) {
+	g := graph.NewGraph()
+	require.NoError(t, g.AddAsset(graph.NewAsset("book_1700.pdf")))
+
+	page := graph.NewEmptyPage()
+	page.Name = "hls__book_1700"
+	root, err := graph.NewBlock(&page, []string{"file-path:: ../assets/book_1700.pdf"}, 0)
+	require.NoError(t, err)
+	page.SetRoot(root)
+
+	assert.True(t, page.IsAnnotationPage())
+
+	asset, ok := g.AnnotatedAsset(&page)
+
+	assert.True(t, ok)
+	assert.Equal(t, "book_1700.pdf", asset.Path)
+}
+
+func TestGraph_PublicGraph_AnnotationAssets(t *testing.T) {
+	g := graph.NewGraph()
+	shownImage := "storages/book_1700/3_6650bf5e-0000-4000-8000-000000000003_1.png"
+	hiddenImage := "storages/book_1700/4_6650bf5e-0000-4000-8000-000000000004_2.png"
+
+	for _, assetPath := range []string{"book_1700.pdf", shownImage, hiddenImage} {
+		require.NoError(t, g.AddAsset(graph.NewAsset(assetPath)))
+	}
+
+	page := graph.NewEmptyPage()
+	page.Name = "hls__book_1700"
+	root, err := graph.NewBlock(&page, []string{"file-path:: ../assets/book_1700.pdf", "public:: true"}, 0)
+	require.NoError(t, err)
+
+	shown, err := graph.NewBlock(&page, []string{
+		"[:span]", "ls-type:: annotation", "hl-page:: 3", "hl-type:: area", "hl-stamp:: 1",
+		"id:: 6650bf5e-0000-4000-8000-000000000003",
+	}, 1)
+	require.NoError(t, err)
+
+	hidden, err := graph.NewBlock(&page, []string{
+		"[:span]", "ls-type:: annotation", "hl-page:: 4", "hl-type:: area", "hl-stamp:: 2",
+		"id:: 6650bf5e-0000-4000-8000-000000000004", "public:: false",
+	}, 1)
+	require.NoError(t, err)
+
+	root.AddChild(shown)
+	root.AddChild(hidden)
+	page.SetRoot(root)
+	require.NoError(t, g.AddPage(&page))
+
+	publicGraph := g.PublicGraph()
+
+	_, ok := publicGraph.FindAsset(shownImage)
+	assert.True(t, ok)
+
+	_, ok = publicGraph.FindAsset("book_1700.pdf")
+	assert.True(t, ok)
+
+	_, ok = publicGraph.FindAsset(hiddenImage)
+	assert.False(t, ok)
+}
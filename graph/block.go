@@ -2,22 +2,26 @@ package graph
 
 import (
 	"fmt"
-	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
 type Block struct {
-	ID         string        `json:"id"`
-	PageName   string        `json:"-"` // Page that contains this block
-	Content    *BlockContent `json:"content"`
-	Properties *PropertyMap  `json:"properties,omitempty"`
-	Depth      int           `json:"depth,omitempty"`
-	Parent     *Block        `json:"-"`
-	Children   []*Block      `json:"children,omitempty"`
+	ID         string         `json:"id"`
+	PageName   string         `json:"-"` // Page that contains this block
+	Content    *BlockContent  `json:"content"`
+	Properties *PropertyMap   `json:"properties,omitempty"`
+	Depth      int            `json:"depth,omitempty"`
+	Parent     *Block         `json:"-"`
+	Children   []*Block       `json:"children,omitempty"`
+	Task       *Task          `json:"task,omitempty"`
+	derivedID  bool           // True if the ID is derived by the graph rather than set with id::
+	location   *time.Location // Time zone for the task and planning timestamps in the content
 }
 
 func NewEmptyBlock() *Block {
@@ -26,13 +30,21 @@ func NewEmptyBlock() *Block {
 		ID:         uuid.New().String(),
 		Content:    content,
 		Properties: NewPropertyMap(),
+		derivedID:  true,
+		location:   time.Local,
 	}
 	content.BlockID = block.ID
 
 	return &block
 }
 
+// NewBlock creates a block from its source lines, reading task times as local time.
 func NewBlock(page *Page, sourceLines []string, depth int) (*Block, error) {
+	return NewBlockInLocation(page, sourceLines, depth, time.Local)
+}
+
+// NewBlockInLocation creates a block from its source lines, reading task times in location.
+func NewBlockInLocation(page *Page, sourceLines []string, depth int, location *time.Location) (*Block, error) {
 	propertyRe := regexp.MustCompile("^([a-zA-Z][a-zA-Z0-9_-]*):: (.*)")
 	contentLines := []string{}
 	properties := NewPropertyMap()
@@ -49,13 +61,15 @@ func NewBlock(page *Page, sourceLines []string, depth int) (*Block, error) {
 		contentLines = append(contentLines, line)
 	}
 
+	// Blocks without an id:: property get a placeholder until the graph derives a stable ID.
 	uuidString := uuid.New().String()
 	idProp, _ := properties.Get("id")
+	derivedID := idProp.Value == ""
 
-	if idProp.Value != "" {
-		uuidString = idProp.Value
-	} else {
+	if derivedID {
 		properties.Set("id", uuidString)
+	} else {
+		uuidString = idProp.Value
 	}
 
 	block := Block{
@@ -63,16 +77,16 @@ func NewBlock(page *Page, sourceLines []string, depth int) (*Block, error) {
 		PageName:   page.Name,
 		Depth:      depth,
 		Properties: properties,
+		derivedID:  derivedID,
+		location:   location,
+		Content:    NewEmptyBlockContent(),
 	}
-	content := strings.Join(contentLines, "\n")
-	blockContent, err := NewBlockContent(&block, content)
+	block.Content.BlockID = block.ID
 
-	if err != nil {
+	if err := block.SetContent(strings.Join(contentLines, "\n")); err != nil {
 		return nil, errors.Wrap(err, "creating block content")
 	}
 
-	block.Content = blockContent
-
 	return &block, nil
 }
 
@@ -81,10 +95,6 @@ func (b *Block) AddChild(child *Block) {
 	child.Parent = b
 }
 
-func (b *Block) Callout() string {
-	return b.Content.Callout
-}
-
 func (b *Block) InContext(g Graph) (string, error) {
 	if b.PageName == "" {
 		return "Setting block context", fmt.Errorf("Block %s has no page name", b.ID)
@@ -112,10 +122,72 @@ func (b *Block) IsPublic() bool {
 	return false
 }
 
+// IsMarkedPrivate returns true if the block, or a block above it other than the page root,
+// sets public:: false.
+func (b *Block) IsMarkedPrivate() bool {
+	for current := b; current != nil && current.Parent != nil; current = current.Parent {
+		if publicProp, ok := current.Properties.Get("public"); ok && !publicProp.Bool() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsTask returns true if the block is a task.
 func (b *Block) IsTask() bool {
-	taskRe := regexp.MustCompile(`^(?:NOW|LATER|TODO|DONE|CANCELED|WAITING)\s`)
+	return b.Task != nil
+}
+
+// watch has the block's content and properties report changes to the graphs holding its page.
+func (b *Block) watch(watchers changeWatchers) {
+	for _, changes := range watchers {
+		if b.Content != nil {
+			b.Content.changes.add(changes)
+		}
+
+		if b.Properties != nil {
+			b.Properties.changes.add(changes)
+		}
+	}
+}
 
-	return taskRe.MatchString(b.Content.Markdown)
+// timeLocation returns the time zone for timestamps in the block's content.
+func (b *Block) timeLocation() *time.Location {
+	if b.location == nil {
+		return time.Local
+	}
+
+	return b.location
+}
+
+// SetContent sets the block's Markdown content and reads its task from it. The :LOGBOOK:
+// drawer belongs to the task rather than the content, so a task keeps its clock entries
+// unless the new content has a logbook.
+func (b *Block) SetContent(markdown string) error {
+	task := ParseTask(markdown, b.timeLocation())
+
+	if task != nil && b.Task != nil && !strings.Contains(markdown, logbookStart) {
+		task.Clock = b.Task.Clock
+	}
+
+	if err := b.Content.SetMarkdown(StripLogbook(markdown)); err != nil {
+		return errors.Wrap(err, "setting markdown content")
+	}
+
+	b.Task = task
+
+	return nil
+}
+
+// Planning returns the block's SCHEDULED and DEADLINE timestamps. Logseq allows them
+// on any block, not just tasks.
+func (b *Block) Planning() (*TaskTimestamp, *TaskTimestamp) {
+	if b.Task != nil {
+		return b.Task.Scheduled, b.Task.Deadline
+	}
+
+	return ParsePlanning(b.Content.Markdown, b.timeLocation())
 }
 
 // Links returns all links found in the block.
@@ -128,10 +200,11 @@ func (b *Block) Links() []Link {
 		links = append(links, Link{
 			Raw:       "",
 			LinksFrom: b.String(),
-			LinkPath:  filepath.Base(banner.Value),
+			LinkPath:  strings.TrimPrefix(banner.Value, "../assets/"),
 			LinkType:  LinkTypeAsset,
 			IsEmbed:   true,
 			Label:     "",
+			Property:  "banner",
 		})
 	}
 
@@ -143,11 +216,75 @@ func (b *Block) Links() []Link {
 			LinkType:  LinkTypeTag,
 			IsEmbed:   false,
 			Label:     tag,
+			Property:  "tags",
 		})
 	}
 
+	linkPaths := make([]string, 0, len(b.Content.Links))
+	for linkPath := range b.Content.Links {
+		linkPaths = append(linkPaths, linkPath)
+	}
+
+	sort.Strings(linkPaths)
+
+	for _, linkPath := range linkPaths {
+		links = append(links, b.Content.Links[linkPath])
+	}
+
+	return append(links, b.propertyLinks()...)
+}
+
+// Properties whose values are never treated as page links.
+var unlinkedProperties = map[string]bool{
+	"alias":  true,
+	"banner": true,
+	"id":     true,
+	"tags":   true,
+	"title":  true,
+}
+
+// propertyLinks returns page links for references in property values,
+// skipping pages the block content already links to.
+func (b *Block) propertyLinks() []Link {
+	links := []Link{}
+	linked := map[string]bool{}
+
 	for _, link := range b.Content.Links {
-		links = append(links, link)
+		if link.IsPage() {
+			linked[link.LinkPath] = true
+		}
+	}
+
+	names := make([]string, 0, len(b.Properties.Properties))
+	for name := range b.Properties.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if unlinkedProperties[name] {
+			continue
+		}
+
+		prop := b.Properties.Properties[name]
+
+		for _, ref := range prop.PageRefs() {
+			if linked[ref] {
+				continue
+			}
+
+			linked[ref] = true
+			links = append(links, Link{
+				Raw:       "",
+				LinksFrom: b.ID,
+				LinkPath:  ref,
+				LinkType:  LinkTypePage,
+				IsEmbed:   false,
+				Label:     ref,
+				Property:  name,
+			})
+		}
 	}
 
 	return links
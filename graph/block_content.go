@@ -11,18 +11,19 @@ import (
 
 // BlockContent represents the content of a block in a Logseq graph.
 type BlockContent struct {
-	BlockID  string          `json:"block_id"` // Block that contains this content
-	Markdown string          `json:"markdown"`
-	HTML     string          `json:"html,omitempty"`
-	Links    map[string]Link `json:"links"`
-	Callout  string          `json:"callout,omitempty"`
+	BlockID   string          `json:"block_id"` // Block that contains this content
+	Markdown  string          `json:"markdown"`
+	HTML      string          `json:"html,omitempty"`
+	Links     map[string]Link `json:"links"`
+	OrgBlocks []OrgBlock      `json:"org_blocks,omitempty"` // Top level #+BEGIN_KIND ... #+END_KIND blocks
+	Query     string          `json:"query,omitempty"`      // EDN of the first advanced query, without its #+BEGIN_QUERY wrapper
+	changes   changeWatchers  // Graphs holding the block with this content
 }
 
 func NewEmptyBlockContent() *BlockContent {
 	return &BlockContent{
 		BlockID:  "",
 		Markdown: "",
-		Callout:  "",
 		Links:    map[string]Link{},
 	}
 }
@@ -43,7 +44,12 @@ func NewBlockContent(block *Block, rawSource string) (*BlockContent, error) {
 func (bc *BlockContent) AddLink(link Link) (Link, error) {
 	log.Debugf("Adding link from block %s: %s", bc.BlockID, link.LinkPath)
 
-	_, ok := bc.FindLink(link.LinkPath)
+	existing, ok := bc.FindLink(link.LinkPath)
+	if ok && existing.IsEmbed {
+		// The target inside an embed macro is found again as a plain link.
+		return Link{}, nil
+	}
+
 	if ok {
 		log.Warnf("Duplicate link in block %s: %s", bc.BlockID, link.LinkPath)
 
@@ -52,6 +58,7 @@ func (bc *BlockContent) AddLink(link Link) (Link, error) {
 
 	link.LinksFrom = bc.BlockID
 	bc.Links[link.LinkPath] = link
+	bc.changes.notify()
 
 	return link, nil
 }
@@ -71,163 +78,73 @@ func (bc *BlockContent) IsCodeBlock() bool {
 
 // IsTask returns true if the block content describes a Logseq task.
 func (bc *BlockContent) IsTask() bool {
-	taskRe := regexp.MustCompile(`^(?:NOW|LATER|TODO|DONE|CANCELED|WAITING)\s`)
-
-	return taskRe.MatchString(bc.Markdown)
+	return taskMarkerRe.MatchString(bc.Markdown)
 }
 
 // SetMarkdown sets the markdown content of the block.
 func (bc *BlockContent) SetMarkdown(markdown string) error {
-	calloutRe := regexp.MustCompile(`(?sm)#\+BEGIN_(\S+)\n(.+?)\n#\+END_(\S+)`)
-	calloutMatch := calloutRe.FindStringSubmatch(markdown)
-
-	if calloutMatch != nil {
-		opener, body, closer := calloutMatch[1], calloutMatch[2], calloutMatch[3]
-
-		if opener != closer {
-			log.Fatalf("(%s) callout mismatch: %s != %s", bc.BlockID, opener, closer)
-		}
-
-		bc.Callout = strings.ToLower(opener)
-		log.Debugf("(%s) found callout: %s", bc.BlockID, bc.Callout)
-
-		markdown = calloutRe.ReplaceAllString(markdown, body)
-		log.Debug("New Markdown: ", markdown)
-	}
-
-	bc.Markdown = markdown
-
-	err := bc.findLinks()
+	nodes, linkSource, err := parseOrgBlocks(markdown)
 	if err != nil {
-		return errors.Wrap(err, "finding links")
+		return errors.Wrap(err, "parsing org blocks")
 	}
 
-	return nil
-}
-
-func (bc *BlockContent) findLinks() error {
-	log.Debug("Finding links in block ", bc.BlockID)
+	bc.changes.notify()
 
-	if bc.IsCodeBlock() {
-		return nil
-	}
-
-	if err := bc.findPageLinks(); err != nil {
-		return errors.Wrap(err, "finding page links")
-	}
+	bc.Markdown = markdown
+	bc.OrgBlocks = OrgBlocks(nodes)
+	bc.Query = ""
 
-	if err := bc.findAssetLinks(); err != nil {
-		return errors.Wrap(err, "finding resource links")
-	}
+	for _, orgBlock := range bc.OrgBlocks {
+		if orgBlock.Kind == OrgBlockQuery {
+			bc.Query = strings.TrimSpace(orgBlock.Body)
+			log.Debugf("(%s) found advanced query", bc.BlockID)
 
-	if err := bc.findTagLinks(); err != nil {
-		return errors.Wrap(err, "finding tag links")
+			break
+		}
 	}
 
-	if err := bc.findBlockLinks(); err != nil {
-		return errors.Wrap(err, "finding block links")
+	if err := bc.findLinks(linkSource); err != nil {
+		return errors.Wrap(err, "finding links")
 	}
 
 	return nil
 }
 
-// findBlockLinks finds block links in the block content.
-func (bc *BlockContent) findBlockLinks() error {
-	blockLinkRe := regexp.MustCompile(`\(\((.+?)\)\)`)
-
-	for _, match := range blockLinkRe.FindAllStringSubmatch(bc.Markdown, -1) {
-		raw, blockID := match[0], match[1]
-		log.Debugf("Found block link: [%s] -> %s", raw, blockID)
-
-		link := Link{
-			Raw:       raw,
-			LinksFrom: bc.BlockID,
-			LinkPath:  blockID,
-			Label:     blockID,
-			LinkType:  LinkTypeBlock,
-			IsEmbed:   false,
-		}
-
-		_, err := bc.AddLink(link)
-		if err != nil {
-			return errors.Wrap(err, "adding block link")
-		}
+// LinkOccurrences returns every link written in the content, in source order. Unlike Links,
+// a target linked more than once shows up each time, so each occurrence can be replaced by its Span.
+func (bc *BlockContent) LinkOccurrences() []Link {
+	_, linkSource, err := parseOrgBlocks(bc.Markdown)
+	if err != nil {
+		return []Link{}
 	}
 
-	return nil
-}
-
-func (bc *BlockContent) findTagLinks() error {
-	tagLinkRe := regexp.MustCompile(`(?:^|\s)#([a-zA-Z][\w/-]+)\b`)
-
-	for _, match := range tagLinkRe.FindAllStringSubmatch(bc.Markdown, -1) {
-		raw, tagName := match[0], match[1]
-
-		log.Debugf("Found tag link: [%s] -> %s", raw, tagName)
-
-		link := Link{
-			Raw:       raw,
-			LinksFrom: bc.BlockID,
-			LinkPath:  tagName,
-			Label:     tagName,
-			LinkType:  LinkTypeTag,
-			IsEmbed:   false,
-		}
-		_, err := bc.AddLink(link)
-
-		if err != nil {
-			return errors.Wrap(err, "adding tag link")
-		}
+	links := parseLinks(linkSource)
+	for i := range links {
+		links[i].LinksFrom = bc.BlockID
 	}
 
-	return nil
+	return links
 }
 
-func (bc *BlockContent) findPageLinks() error {
-	pageLinkRe := regexp.MustCompile(`\[\[(.+?)\]\]`)
-
-	for _, match := range pageLinkRe.FindAllStringSubmatch(bc.Markdown, -1) {
-		raw, pageName := match[0], match[1]
-		log.Debugf("Found page link: [%s] -> %s", raw, pageName)
-		link := Link{
-			Raw:       raw,
-			LinksFrom: bc.BlockID,
-			LinkPath:  pageName,
-			Label:     pageName,
-			LinkType:  LinkTypePage,
-			IsEmbed:   false,
-		}
-		_, err := bc.AddLink(link)
+// findLinks finds links in the block content's Markdown, with verbatim Org block bodies blanked out.
+func (bc *BlockContent) findLinks(linkSource string) error {
+	log.Debug("Finding links in block ", bc.BlockID)
 
-		if err != nil {
-			return errors.Wrap(err, "adding page link")
-		}
-	}
+	links := parseLinks(linkSource)
 
-	return nil
-}
+	// Page and block embeds first, so a plain link to the same target doesn't hide the embed.
+	for _, embedPass := range []bool{true, false} {
+		for _, link := range links {
+			if (link.IsEmbed && !link.IsAsset()) != embedPass {
+				continue
+			}
 
-func (bc *BlockContent) findAssetLinks() error {
-	// a regular expression to match URLs, which may have embedded parentheses
-	// https://stackoverflow.com/a/3809435
-	assetLinkRe := regexp.MustCompile(`(!?)\[(.*?)\]\(\.\./assets/(.*?)\)`)
-
-	for _, match := range assetLinkRe.FindAllStringSubmatch(bc.Markdown, -1) {
-		raw, isEmbed, label, assetFile := match[0], match[1], match[2], match[3]
-		log.Debugf("Found resource link: ->%s<- label=%s uri=%s", raw, label, assetFile)
-
-		link := Link{
-			Raw:       raw,
-			LinksFrom: bc.BlockID,
-			LinkPath:  assetFile,
-			Label:     label,
-			LinkType:  LinkTypeAsset,
-			IsEmbed:   isEmbed == "!",
-		}
+			link.LinksFrom = bc.BlockID
+			log.Debugf("Found %s link: [%s] -> %s", link.LinkType, link.Raw, link.LinkPath)
 
-		_, err := bc.AddLink(link)
-		if err != nil {
-			return errors.Wrap(err, "adding asset link")
+			if _, err := bc.AddLink(link); err != nil {
+				return errors.Wrapf(err, "adding %s link", link.LinkType)
+			}
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"export-logseq/graph"
 )
@@ -142,3 +143,185 @@ func TestBlockContent_SetMarkdown(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, markdown, content.Markdown)
 }
+
+func TestBlockContent_SetMarkdown_OrgLinks(t *testing.T) {
+	content := graph.NewEmptyBlockContent()
+	err := content.SetMarkdown("Read [[Dune][the novel]] and [[https://example.com][a site]]")
+
+	assert.NoError(t, err)
+	assert.Len(t, content.Links, 1)
+
+	link, ok := content.FindLink("Dune")
+
+	assert.True(t, ok)
+	assert.Equal(t, "the novel", link.Label)
+	assert.True(t, link.Labelled)
+	assert.Equal(t, graph.LinkTypePage, link.LinkType)
+}
+
+func TestBlockContent_SetMarkdown_Embeds(t *testing.T) {
+	blockID := gofakeit.UUID()
+	content := graph.NewEmptyBlockContent()
+	err := content.SetMarkdown("{{embed [[Overview]]}} and {{embed ((" + blockID + "))}}")
+
+	assert.NoError(t, err)
+	assert.Len(t, content.Links, 2)
+
+	pageLink, ok := content.FindLink("Overview")
+
+	assert.True(t, ok)
+	assert.True(t, pageLink.IsEmbed)
+	assert.Equal(t, graph.LinkTypePage, pageLink.LinkType)
+	assert.Equal(t, "{{embed [[Overview]]}}", pageLink.Raw)
+
+	blockLink, ok := content.FindLink(blockID)
+
+	assert.True(t, ok)
+	assert.True(t, blockLink.IsEmbed)
+	assert.Equal(t, graph.LinkTypeBlock, blockLink.LinkType)
+}
+
+func TestBlockContent_SetMarkdown_AdvancedQuery(t *testing.T) {
+	content := graph.NewEmptyBlockContent()
+	markdown := "Reading list\n#+BEGIN_QUERY\n{:query [:find (pull ?b [*]) :where [?b :block/marker \"TODO\"]]}\n#+END_QUERY"
+	err := content.SetMarkdown(markdown)
+
+	assert.NoError(t, err)
+	assert.Equal(t, markdown, content.Markdown)
+	assert.Equal(t, `{:query [:find (pull ?b [*]) :where [?b :block/marker "TODO"]]}`, content.Query)
+	assert.Len(t, content.OrgBlocks, 1)
+	assert.Equal(t, graph.OrgBlockQuery, content.OrgBlocks[0].Kind)
+}
+
+func TestBlockContent_SetMarkdown_OrgBlocks(t *testing.T) {
+	content := graph.NewEmptyBlockContent()
+	err := content.SetMarkdown("#+BEGIN_NOTE\nSee [[Guide]]\n#+END_NOTE\n#+BEGIN_SRC go\nfmt.Println(\"[[Not a link]]\")\n#+END_SRC")
+
+	assert.NoError(t, err)
+	assert.Len(t, content.OrgBlocks, 2)
+	assert.Equal(t, "note", content.OrgBlocks[0].Kind)
+	assert.Equal(t, "src", content.OrgBlocks[1].Kind)
+	assert.Equal(t, "go", content.OrgBlocks[1].Language())
+	assert.Len(t, content.Links, 1)
+
+	_, ok := content.FindLink("Guide")
+
+	assert.True(t, ok)
+}
+
+func TestBlockContent_SetMarkdown_OrgBlockMismatch(t *testing.T) {
+	content := graph.NewEmptyBlockContent()
+	err := content.SetMarkdown("#+BEGIN_NOTE\ntext\n#+END_TIP")
+
+	assert.ErrorContains(t, err, "#+BEGIN_NOTE closed by #+END_TIP")
+}
+
+func TestBlockContent_SetMarkdown_LinkSyntax(t *testing.T) {
+	linkTests := []struct {
+		name     string
+		markdown string
+		want     []graph.Link
+	}{
+		{
+			"inline code",
+			"Use `[[Not a link]]` or `#nope` with [[Page]]",
+			[]graph.Link{{Raw: "[[Page]]", LinkPath: "Page", Label: "Page", LinkType: graph.LinkTypePage, Span: &graph.Span{Start: 37, End: 45}}},
+		},
+		{
+			"next to a code fence",
+			"See [[Page]]\n```\n[[Fenced]]\n```",
+			[]graph.Link{{Raw: "[[Page]]", LinkPath: "Page", Label: "Page", LinkType: graph.LinkTypePage, Span: &graph.Span{Start: 4, End: 12}}},
+		},
+		{
+			"URL fragment",
+			"Read https://example.com/docs#install #setup",
+			[]graph.Link{{Raw: "#setup", LinkPath: "setup", Label: "setup", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 38, End: 44}}},
+		},
+		{
+			"single letter tag",
+			"Grade #a",
+			[]graph.Link{{Raw: "#a", LinkPath: "a", Label: "a", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 6, End: 8}}},
+		},
+		{
+			"unicode tag",
+			"Today #日記",
+			[]graph.Link{{Raw: "#日記", LinkPath: "日記", Label: "日記", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 6, End: 13}}},
+		},
+		{
+			"tag starting with a digit",
+			"Plans #2024-goals.",
+			[]graph.Link{{Raw: "#2024-goals", LinkPath: "2024-goals", Label: "2024-goals", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 6, End: 17}}},
+		},
+		{
+			"tag in parentheses",
+			"Noted (#idea) and #todo, #v1.2: done",
+			[]graph.Link{
+				{Raw: "#idea", LinkPath: "idea", Label: "idea", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 7, End: 12}},
+				{Raw: "#todo", LinkPath: "todo", Label: "todo", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 18, End: 23}},
+				{Raw: "#v1.2", LinkPath: "v1.2", Label: "v1.2", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 25, End: 30}},
+			},
+		},
+		{
+			"task priority",
+			"TODO [#A] call (#b)",
+			[]graph.Link{{Raw: "#b", LinkPath: "b", Label: "b", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 16, End: 18}}},
+		},
+		{
+			"multi word tag",
+			"#[[Reading List]] item",
+			[]graph.Link{{Raw: "#[[Reading List]]", LinkPath: "Reading List", Label: "Reading List", LinkType: graph.LinkTypeTag, Span: &graph.Span{Start: 0, End: 17}}},
+		},
+		{
+			"labelled page link",
+			"Read [the novel]([[Dune]]) tonight",
+			[]graph.Link{{Raw: "[the novel]([[Dune]])", LinkPath: "Dune", Label: "the novel", LinkType: graph.LinkTypePage, Labelled: true, Span: &graph.Span{Start: 5, End: 26}}},
+		},
+		{
+			"labelled block link",
+			"As [noted](((6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11))) before",
+			[]graph.Link{{Raw: "[noted](((6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11)))", LinkPath: "6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11", Label: "noted", LinkType: graph.LinkTypeBlock, Labelled: true, Span: &graph.Span{Start: 3, End: 52}}},
+		},
+		{
+			"block link labelled with its uuid",
+			"[6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11](((6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11)))",
+			[]graph.Link{{Raw: "[6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11](((6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11)))", LinkPath: "6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11", Label: "6650bf5e-2d5b-4c7e-a3b0-5c0a3a1c8f11", LinkType: graph.LinkTypeBlock, Labelled: true, Span: &graph.Span{Start: 0, End: 80}}},
+		},
+		{
+			"asset image",
+			"![chart](../assets/chart.png)",
+			[]graph.Link{{Raw: "![chart](../assets/chart.png)", LinkPath: "chart.png", Label: "chart", LinkType: graph.LinkTypeAsset, IsEmbed: true, Span: &graph.Span{Start: 0, End: 29}}},
+		},
+	}
+
+	for _, tt := range linkTests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := graph.NewEmptyBlockContent()
+			err := content.SetMarkdown(tt.markdown)
+
+			assert.NoError(t, err)
+			assert.Len(t, content.Links, len(tt.want))
+
+			for _, want := range tt.want {
+				link, ok := content.FindLink(want.LinkPath)
+
+				assert.True(t, ok)
+				assert.Equal(t, want, link)
+				assert.Equal(t, want.Raw, tt.markdown[link.Span.Start:link.Span.End])
+			}
+		})
+	}
+}
+
+func TestBlockContent_LinkOccurrences(t *testing.T) {
+	content := graph.NewEmptyBlockContent()
+	require.NoError(t, content.SetMarkdown("[the book]([[Dune]]) is [[Dune]]\n#+BEGIN_SRC\n[[Code]]\n#+END_SRC"))
+
+	occurrences := content.LinkOccurrences()
+
+	assert.Len(t, content.Links, 1)
+	require.Len(t, occurrences, 2)
+	assert.True(t, occurrences[0].Labelled)
+	assert.Equal(t, graph.Span{Start: 0, End: 20}, *occurrences[0].Span)
+	assert.False(t, occurrences[1].Labelled)
+	assert.Equal(t, graph.Span{Start: 24, End: 32}, *occurrences[1].Span)
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// blockIDSpace namespaces the IDs derived for blocks without an id:: property.
+var blockIDSpace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/brianwisti/ExportLogseq/block"))
+
+// DeriveBlockID returns a stable ID for a block from its page name, its position in the
+// page tree (like "0.2.1"), and its content, so anchors don't change between exports.
+func DeriveBlockID(pageName, position, content string) string {
+	source := strings.ToLower(pageName) + "\x00" + position + "\x00" + content
+
+	return uuid.NewSHA1(blockIDSpace, []byte(source)).String()
+}
+
+// setID renames a block, along with the links it holds.
+func (b *Block) setID(id string) {
+	b.ID = id
+
+	if _, ok := b.Properties.Get("id"); ok {
+		b.Properties.Set("id", id)
+	}
+
+	if b.Content == nil {
+		return
+	}
+
+	b.Content.BlockID = id
+	b.Content.changes.notify()
+
+	for path, link := range b.Content.Links {
+		link.LinksFrom = id
+		b.Content.Links[path] = link
+	}
+}
+
+// blockPositions returns the tree position of each block under root, like "0.2.1" for
+// the second child of the third child of root.
+func blockPositions(root *Block) map[*Block]string {
+	positions := map[*Block]string{}
+
+	var walk func(block *Block, position string)
+	walk = func(block *Block, position string) {
+		positions[block] = position
+
+		for i, child := range block.Children {
+			walk(child, position+"."+strconv.Itoa(i))
+		}
+	}
+
+	if root != nil {
+		walk(root, "0")
+	}
+
+	return positions
+}
+
+// DeriveBlockIDs gives each block on a page without an id:: property a stable ID. Call it
+// once the page is named and its block tree is built, before adding it to the graph.
+// An ID already used by another block gets a counter added to its position until it's free.
+func (g *Graph) DeriveBlockIDs(page *Page) {
+	positions := blockPositions(page.Root)
+	taken := map[string]bool{}
+
+	for _, block := range page.AllBlocks {
+		if !block.derivedID {
+			taken[block.ID] = true
+		}
+	}
+
+	for i, block := range page.AllBlocks {
+		if !block.derivedID {
+			continue
+		}
+
+		position, ok := positions[block]
+		if !ok {
+			position = "~" + strconv.Itoa(i)
+		}
+
+		content := ""
+		if block.Content != nil {
+			content = block.Content.Markdown
+		}
+
+		id := DeriveBlockID(page.Name, position, content)
+
+		for n := 1; taken[id] || g.blockIDTaken(id, page); n++ {
+			id = DeriveBlockID(page.Name, position+"#"+strconv.Itoa(n), content)
+		}
+
+		taken[id] = true
+		block.setID(id)
+	}
+}
+
+// blockIDTaken returns true if a block on another page in the graph has the ID.
+// Blocks of a placeholder the page replaces don't count.
+func (g *Graph) blockIDTaken(id string, page *Page) bool {
+	other, ok := g.Blocks[id]
+
+	return ok && !strings.EqualFold(other.PageName, page.Name)
+}
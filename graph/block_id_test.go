@@ -0,0 +1,79 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+)
+
+func TestDeriveBlockID(t *testing.T) {
+	id := graph.DeriveBlockID("Page", "0.1", "content")
+
+	assert.Equal(t, id, graph.DeriveBlockID("page", "0.1", "content"))
+	assert.NotEqual(t, id, graph.DeriveBlockID("Page", "0.2", "content"))
+	assert.NotEqual(t, id, graph.DeriveBlockID("Page", "0.1", "changed"))
+	assert.Len(t, id, 36)
+}
+
+func TestGraph_DeriveBlockIDs(t *testing.T) {
+	g := graph.NewGraph()
+	page := Page()
+	child, err := graph.NewBlock(&page, []string{"child"}, 1)
+	require.NoError(t, err)
+
+	pinned, err := graph.NewBlock(&page, []string{"pinned", "id:: 6650bf5e-0000-4000-8000-000000000001"}, 1)
+	require.NoError(t, err)
+
+	page.Root.AddChild(child)
+	page.Root.AddChild(pinned)
+	page.SetRoot(page.Root)
+	g.DeriveBlockIDs(&page)
+
+	assert.Equal(t, graph.DeriveBlockID(page.Name, "0.0", "child"), child.ID)
+	assert.Equal(t, child.ID, child.Content.BlockID)
+
+	idProp, ok := child.Properties.Get("id")
+	require.True(t, ok)
+	assert.Equal(t, child.ID, idProp.Value)
+	assert.Equal(t, "6650bf5e-0000-4000-8000-000000000001", pinned.ID)
+}
+
+func TestGraph_DeriveBlockIDs_Collision(t *testing.T) {
+	g := graph.NewGraph()
+	page := Page()
+	taken := graph.DeriveBlockID(page.Name, "0.0", "child")
+
+	other := Page()
+	other.Name = page.Name + "-other"
+	claimed, err := graph.NewBlock(&other, []string{"claimed", "id:: " + taken}, 1)
+	require.NoError(t, err)
+	other.Root.AddChild(claimed)
+	other.SetRoot(other.Root)
+	require.NoError(t, g.AddPage(&other))
+
+	child, err := graph.NewBlock(&page, []string{"child"}, 1)
+	require.NoError(t, err)
+	page.Root.AddChild(child)
+	page.SetRoot(page.Root)
+	g.DeriveBlockIDs(&page)
+
+	assert.NotEqual(t, taken, child.ID)
+	assert.Equal(t, graph.DeriveBlockID(page.Name, "0.0#1", "child"), child.ID)
+}
+
+func TestGraph_DeriveBlockIDs_UpdatesLinks(t *testing.T) {
+	g := graph.NewGraph()
+	page := Page()
+	block, err := graph.NewBlock(&page, []string{"see [[Other]]"}, 1)
+	require.NoError(t, err)
+	page.Root.AddChild(block)
+	page.SetRoot(page.Root)
+	g.DeriveBlockIDs(&page)
+
+	link, ok := block.Content.FindLink("Other")
+	require.True(t, ok)
+	assert.Equal(t, block.ID, link.LinksFrom)
+}
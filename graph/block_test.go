@@ -143,3 +143,39 @@ func TestBlock_Tags(t *testing.T) {
 
 	assert.Contains(t, block.Tags(), tag)
 }
+
+func TestBlock_Links_BannerInAssetSubfolder(t *testing.T) {
+	block := graph.NewEmptyBlock()
+	block.SetProperty("banner", "../assets/banners/sunset.jpg")
+	links := block.Links()
+
+	assert.Len(t, links, 1)
+	assert.Equal(t, "banners/sunset.jpg", links[0].LinkPath)
+	assert.Equal(t, graph.LinkTypeAsset, links[0].LinkType)
+}
+
+func TestBlock_Links_FromProperties(t *testing.T) {
+	block := graph.NewEmptyBlock()
+	block.SetProperty("related", "[[Project X]], [[Alice]]")
+	block.SetProperty("status", "#active")
+	block.SetProperty("alias", "[[Other Name]]")
+	links := block.Links()
+
+	require.Len(t, links, 3)
+	assert.Equal(t, graph.Link{
+		LinksFrom: block.ID, LinkPath: "Project X", LinkType: graph.LinkTypePage, Label: "Project X", Property: "related",
+	}, links[0])
+	assert.Equal(t, "Alice", links[1].LinkPath)
+	assert.Equal(t, "active", links[2].LinkPath)
+	assert.Equal(t, "status", links[2].Property)
+	assert.Equal(t, graph.LinkTypePage, links[2].LinkType)
+}
+
+func TestBlock_Links_FromPropertiesSkipsContentLinks(t *testing.T) {
+	block := graph.NewEmptyBlock()
+	block.SetProperty("related", "[[Project X]]")
+	contentLink, _ := block.Content.AddLink(graph.Link{LinkPath: "Project X", LinkType: graph.LinkTypePage})
+	links := block.Links()
+
+	assert.Equal(t, []graph.Link{contentLink}, links)
+}
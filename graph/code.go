@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// FindCode returns where the code spans, code blocks, and <pre> blocks are in Markdown
+// content, in source order. Spans cover the code, not the backticks or fences around it.
+func FindCode(markdown string) []Span {
+	source := []byte(markdown)
+	document := linkParser.Parse(text.NewReader(source))
+	found := []Span{}
+
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := node.(type) {
+		case *ast.CodeSpan:
+			if span, ok := childTextSpan(node); ok {
+				found = append(found, span)
+			}
+
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			if span, ok := linesSpan(node.Lines()); ok {
+				found = append(found, span)
+			}
+		case *ast.HTMLBlock:
+			span, ok := linesSpan(node.Lines())
+			if !ok || !bytes.HasPrefix(source[span.Start:span.End], []byte("<pre")) {
+				break
+			}
+
+			if node.HasClosure() {
+				span.End = node.ClosureLine.Stop
+			}
+
+			found = append(found, span)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return found
+}
+
+// childTextSpan returns the span from the first text child of a node to the end of the last.
+func childTextSpan(node ast.Node) (Span, bool) {
+	first, firstOK := node.FirstChild().(*ast.Text)
+	last, lastOK := node.LastChild().(*ast.Text)
+
+	if !firstOK || !lastOK {
+		return Span{}, false
+	}
+
+	return Span{Start: first.Segment.Start, End: last.Segment.Stop}, true
+}
+
+// linesSpan returns the span from the start of the first line to the end of the last.
+func linesSpan(lines *text.Segments) (Span, bool) {
+	if lines.Len() == 0 {
+		return Span{}, false
+	}
+
+	return Span{Start: lines.At(0).Start, End: lines.At(lines.Len() - 1).Stop}, true
+}
@@ -0,0 +1,32 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestFindCode(t *testing.T) {
+	codeTests := []struct {
+		name     string
+		markdown string
+		want     []string
+	}{
+		{"no code", "plain [[text]]", []string{}},
+		{"code span", "wrote `[[Other]]` and [[Other]]", []string{"[[Other]]"}},
+		{"fenced code", "before\n```\n{{< x >}}\n```\nafter `y`", []string{"{{< x >}}\n", "y"}},
+		{"pre block", "<pre>\n[[Other]]\n</pre>", []string{"<pre>\n[[Other]]\n</pre>"}},
+	}
+
+	for _, tt := range codeTests {
+		found := []string{}
+
+		for _, span := range graph.FindCode(tt.markdown) {
+			found = append(found, tt.markdown[span.Start:span.End])
+		}
+
+		assert.Equal(t, tt.want, found, tt.name)
+	}
+}
@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// FileNameFormat describes how Logseq encodes page names into file names.
+type FileNameFormat string
+
+const (
+	FileNameFormatLegacy       FileNameFormat = "legacy"
+	FileNameFormatTripleLowbar FileNameFormat = "triple-lowbar"
+)
+
+// Config holds the graph settings read from logseq/config.edn.
+type Config struct {
+	PagesDirectory         string         `json:"pages_directory"`
+	JournalsDirectory      string         `json:"journals_directory"`
+	WhiteboardsDirectory   string         `json:"whiteboards_directory"`
+	FileNameFormat         FileNameFormat `json:"file_name_format"`
+	JournalPageTitleFormat string         `json:"journal_page_title_format"`
+	JournalFileNameFormat  string         `json:"journal_file_name_format"`
+	Hidden                 []string       `json:"hidden"`
+	IgnoredFiles           []string       `json:"ignored_files"`
+}
+
+// NewConfig returns a Config with Logseq's defaults.
+func NewConfig() Config {
+	return Config{
+		PagesDirectory:         "pages",
+		JournalsDirectory:      "journals",
+		WhiteboardsDirectory:   "whiteboards",
+		FileNameFormat:         FileNameFormatLegacy,
+		JournalPageTitleFormat: "MMM do, yyyy",
+		JournalFileNameFormat:  "yyyy_MM_dd",
+		Hidden:                 []string{},
+		IgnoredFiles:           []string{"Templates.md"},
+	}
+}
+
+// IsHidden returns true if a path relative to the graph directory should not be loaded.
+// Dot files and directories are always hidden, as are paths under a :hidden entry.
+func (c *Config) IsHidden(pathInGraph string) bool {
+	pathInGraph = filepath.ToSlash(pathInGraph)
+
+	for _, step := range strings.Split(pathInGraph, "/") {
+		if strings.HasPrefix(step, ".") {
+			return true
+		}
+	}
+
+	for _, hidden := range c.Hidden {
+		hidden = strings.Trim(filepath.ToSlash(hidden), "/")
+
+		if hidden == "" {
+			continue
+		}
+
+		if pathInGraph == hidden || strings.HasPrefix(pathInGraph, hidden+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsIgnoredFile returns true if a file's base name is in the ignored files list.
+func (c *Config) IsIgnoredFile(fileName string) bool {
+	baseName := filepath.Base(fileName)
+
+	for _, ignored := range c.IgnoredFiles {
+		if baseName == ignored {
+			return true
+		}
+	}
+
+	return false
+}
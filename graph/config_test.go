@@ -0,0 +1,46 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestConfig_NewConfig(t *testing.T) {
+	config := graph.NewConfig()
+
+	assert.Equal(t, "pages", config.PagesDirectory)
+	assert.Equal(t, "journals", config.JournalsDirectory)
+	assert.Equal(t, graph.FileNameFormatLegacy, config.FileNameFormat)
+}
+
+func TestConfig_IsHidden(t *testing.T) {
+	isHiddenTests := []struct {
+		path string
+		want bool
+	}{
+		{"pages/note.md", false},
+		{".recycle/note.md", true},
+		{"pages/.note.md", true},
+		{"archived/note.md", true},
+		{"archived", true},
+		{"archived-notes/note.md", false},
+		{"pages/drafts/note.md", true},
+	}
+
+	config := graph.NewConfig()
+	config.Hidden = []string{"/archived", "pages/drafts/", ""}
+
+	for _, tt := range isHiddenTests {
+		assert.Equal(t, tt.want, config.IsHidden(tt.path), tt.path)
+	}
+}
+
+func TestConfig_IsIgnoredFile(t *testing.T) {
+	config := graph.NewConfig()
+
+	assert.True(t, config.IsIgnoredFile("pages/Templates.md"))
+	assert.False(t, config.IsIgnoredFile("pages/Contents.md"))
+}
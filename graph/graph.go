@@ -10,19 +10,23 @@ import (
 type Graph struct {
 	GraphDir          string            `json:"-"`
 	Name              string            `json:"name"`
+	Config            Config            `json:"-"`
 	HoistedNamespaces []string          `json:"-"`
 	Pages             map[string]*Page  `json:"pages"`
 	Blocks            map[string]*Block `json:"-"`
 	Assets            []Asset           `json:"assets"`
-	links             []Link
+	idx               *graphIndex
+	changes           *graphChanges
 }
 
 func NewGraph() Graph {
 	return Graph{
+		Config:            NewConfig(),
 		Pages:             map[string]*Page{},
 		Assets:            []Asset{},
 		Blocks:            map[string]*Block{},
 		HoistedNamespaces: []string{},
+		changes:           &graphChanges{},
 	}
 }
 
@@ -55,6 +59,10 @@ func (g *Graph) AddPage(page *Page) error {
 	if existingPage != nil {
 		if existingPage.IsPlaceholder() {
 			log.Debug("Replacing placeholder page: ", page.Name)
+
+			for _, block := range existingPage.AllBlocks {
+				delete(g.Blocks, block.ID)
+			}
 		} else {
 			return PageExistsError{page.Name}
 		}
@@ -62,6 +70,9 @@ func (g *Graph) AddPage(page *Page) error {
 
 	g.Pages[pageKey] = page
 
+	g.watch(page)
+	g.indexPage(page, existingPage != nil)
+
 	for _, tag := range page.Tags() {
 		tagKey := strings.ToLower(tag)
 		_, ok := g.Pages[tagKey]
@@ -113,8 +124,10 @@ func (g *Graph) AddPlaceholderPage(name string) (*Page, error) {
 
 	page.Name = name
 	page.Title = title
+	page.Root.PageName = name
 
 	page.Root.Properties.Set("public", "true")
+	g.DeriveBlockIDs(&page)
 
 	return &page, g.AddPage(&page)
 }
@@ -141,44 +154,35 @@ func (g *Graph) FindAsset(path string) (Asset, bool) {
 	return Asset{}, false
 }
 
-// FindLinksToPage returns all links to a Page.
+// FindLinksToPage returns all page links to a Page.
 func (g *Graph) FindLinksToPage(page *Page) []Link {
-	log.Debug("Finding links in graph to: ", page)
-
-	links := []Link{}
-
-	for _, link := range g.PageLinks() {
-		linkTarget := link.LinkPath
-		log.Debug("Checking link from ", link.LinksFrom, " to ", linkTarget)
-
-		if page.Name == linkTarget {
-			log.Debug("Found link to ", page.Name, " in ", link.LinksFrom)
-			links = append(links, link)
-		}
-	}
-
-	return links
+	return g.findLinksToPage(page, LinkTypePage)
 }
 
 // FindTagLinksToPage returns all tag links to a Page.
 func (g *Graph) FindTagLinksToPage(page *Page) []Link {
-	log.Debug("Finding tag links in graph to: ", page)
+	return g.findLinksToPage(page, LinkTypeTag)
+}
+
+func (g *Graph) findLinksToPage(page *Page, linkType LinkType) []Link {
+	log.Debugf("Finding %s links in graph to: %s", linkType, page.Name)
 
 	links := []Link{}
 
-	for _, link := range g.Links() {
-		if link.LinkType != LinkTypeTag {
-			continue
-		}
+	g.withIndex(func(idx *graphIndex) {
+		links = append(links, idx.inbound[strings.ToLower(page.Name)][linkType]...)
+	})
 
-		linkTarget := link.LinkPath
-		log.Debug("Checking link from ", link.LinksFrom, " to ", linkTarget)
+	return links
+}
 
-		if page.Name == linkTarget {
-			log.Debug("Found link to ", page.Name, " in ", link.LinksFrom)
-			links = append(links, link)
-		}
-	}
+// FindLinksToBlock returns all block references to a Block.
+func (g *Graph) FindLinksToBlock(block *Block) []Link {
+	links := []Link{}
+
+	g.withIndex(func(idx *graphIndex) {
+		links = append(links, idx.blockLinks[block.ID]...)
+	})
 
 	return links
 }
@@ -192,12 +196,12 @@ func (g *Graph) FindPage(name string) (*Page, error) {
 		return page, nil
 	}
 
-	for _, page := range g.Pages {
-		for _, alias := range page.Aliases() {
-			if alias == name {
-				return page, nil
-			}
-		}
+	g.withIndex(func(idx *graphIndex) {
+		page, ok = idx.aliases[pageKey]
+	})
+
+	if ok {
+		return page, nil
 	}
 
 	return nil, PageNotFoundError{name}
@@ -205,16 +209,13 @@ func (g *Graph) FindPage(name string) (*Page, error) {
 
 // Links returns all links found in the graph.
 func (g *Graph) Links() []Link {
-	if g.links == nil {
-		links := []Link{}
-		for _, page := range g.Pages {
-			links = append(links, page.Links()...)
-		}
+	links := []Link{}
 
-		g.links = links
-	}
+	g.withIndex(func(idx *graphIndex) {
+		links = append(links, idx.links...)
+	})
 
-	return g.links
+	return links
 }
 
 // AssetLinks returns all asset links found in the graph.
@@ -262,6 +263,7 @@ func (g *Graph) PublicGraph() Graph {
 	publicGraph := NewGraph()
 	publicGraph.GraphDir = g.GraphDir
 	publicGraph.Name = g.Name
+	publicGraph.Config = g.Config
 
 	for _, page := range g.Pages {
 		if page.IsPublic() {
@@ -269,6 +271,22 @@ func (g *Graph) PublicGraph() Graph {
 		}
 	}
 
+	// Annotation pages use their PDF and area highlight images without linking to them.
+	for _, page := range publicGraph.Pages {
+		for _, assetPath := range page.AnnotationAssetPaths() {
+			asset, ok := g.FindAsset(assetPath)
+			if !ok {
+				continue
+			}
+
+			if _, ok := publicGraph.FindAsset(assetPath); !ok {
+				if err := publicGraph.AddAsset(asset); err != nil {
+					log.Fatalf("adding asset %s to public graph: %v", assetPath, err)
+				}
+			}
+		}
+	}
+
 	// Add assets that are linked from public pages.
 	for _, link := range publicGraph.AssetLinks() {
 		if link.LinkType == LinkTypeAsset {
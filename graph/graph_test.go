@@ -356,3 +356,162 @@ func TestGraph_PublicGraph_WithAssetInPublicPage(t *testing.T) {
 	assert.NotEmpty(t, links)
 	assert.Contains(t, links, link)
 }
+
+func TestGraph_FindLinksToPage_FromProperty(t *testing.T) {
+	g := graph.NewGraph()
+	fromPage, toPage := Page(), Page()
+	fromPage.Root.SetProperty("related", "[["+toPage.Name+"]]")
+	require.NoError(t, g.AddPage(&fromPage))
+	require.NoError(t, g.AddPage(&toPage))
+
+	links := g.FindLinksToPage(&toPage)
+
+	require.Len(t, links, 1)
+	assert.Equal(t, fromPage.Root.ID, links[0].LinksFrom)
+	assert.Equal(t, "related", links[0].Property)
+}
+
+func TestGraph_FindPage_WithAlias_AfterIndexBuilt(t *testing.T) {
+	g := graph.NewGraph()
+	first := Page()
+	require.NoError(t, g.AddPage(&first))
+	_, err := g.FindPage("Alias")
+	require.Error(t, err)
+
+	page := Page()
+	page.Root.Properties.Set("alias", "Alias")
+	require.NoError(t, g.AddPage(&page))
+	foundPage, err := g.FindPage("alias")
+
+	require.NoError(t, err)
+	assert.Equal(t, &page, foundPage)
+}
+
+func TestGraph_FindLinksToPage_AddedAfterIndexBuilt(t *testing.T) {
+	g := graph.NewGraph()
+	toPage := Page()
+	require.NoError(t, g.AddPage(&toPage))
+	assert.Empty(t, g.FindLinksToPage(&toPage))
+
+	fromPage := Page()
+	fromPage.Root.SetProperty("related", "[["+toPage.Name+"]]")
+	require.NoError(t, g.AddPage(&fromPage))
+
+	links := g.FindLinksToPage(&toPage)
+	require.Len(t, links, 1)
+	assert.Equal(t, fromPage.Root.ID, links[0].LinksFrom)
+}
+
+func TestGraph_FindLinksToPage_CaseInsensitive(t *testing.T) {
+	g := graph.NewGraph()
+	fromPage, toPage := Page(), Page()
+	fromPage.Root.SetProperty("related", "[["+strings.ToUpper(toPage.Name)+"]]")
+	require.NoError(t, g.AddPage(&fromPage))
+	require.NoError(t, g.AddPage(&toPage))
+
+	assert.Len(t, g.FindLinksToPage(&toPage), 1)
+}
+
+func TestGraph_FindTagLinksToPage(t *testing.T) {
+	g := graph.NewGraph()
+	fromPage := Page()
+	fromPage.Root.SetProperty("tags", "Topic")
+	require.NoError(t, g.AddPage(&fromPage))
+
+	topic, err := g.FindPage("topic")
+	require.NoError(t, err)
+
+	links := g.FindTagLinksToPage(topic)
+	require.Len(t, links, 1)
+	assert.Empty(t, g.FindLinksToPage(topic))
+}
+
+func TestGraph_FindLinksToBlock(t *testing.T) {
+	g := graph.NewGraph()
+	toPage, fromPage := Page(), Page()
+	require.NoError(t, g.AddPage(&toPage))
+	require.NoError(t, g.AddPage(&fromPage))
+
+	assert.Empty(t, g.FindLinksToBlock(toPage.Root))
+
+	_, err := fromPage.Root.Content.AddLink(graph.Link{LinkPath: toPage.Root.ID, LinkType: graph.LinkTypeBlock})
+	require.NoError(t, err)
+
+	links := g.FindLinksToBlock(toPage.Root)
+	require.Len(t, links, 1)
+	assert.Equal(t, fromPage.Root.ID, links[0].LinksFrom)
+}
+
+func TestGraph_FindPage_AliasSetAfterIndexBuilt(t *testing.T) {
+	g := graph.NewGraph()
+	page := Page()
+	require.NoError(t, g.AddPage(&page))
+	_, err := g.FindPage("Nickname")
+	require.Error(t, err)
+
+	page.Root.SetProperty("alias", "Nickname")
+	foundPage, err := g.FindPage("nickname")
+
+	require.NoError(t, err)
+	assert.Equal(t, &page, foundPage)
+}
+
+func TestGraph_FindLinksToPage_ContentChangedAfterIndexBuilt(t *testing.T) {
+	g := graph.NewGraph()
+	fromPage, toPage := Page(), Page()
+	require.NoError(t, g.AddPage(&fromPage))
+	require.NoError(t, g.AddPage(&toPage))
+	assert.Empty(t, g.FindLinksToPage(&toPage))
+
+	require.NoError(t, fromPage.Root.Content.SetMarkdown("see [["+toPage.Name+"]]"))
+
+	assert.Len(t, g.FindLinksToPage(&toPage), 1)
+}
+
+func TestGraph_Links_ReturnsCopy(t *testing.T) {
+	g := graph.NewGraph()
+	page := Page()
+	require.NoError(t, page.Root.Content.SetMarkdown("see [[Other]]"))
+	require.NoError(t, g.AddPage(&page))
+
+	links := g.Links()
+	require.NotEmpty(t, links)
+	links[0].LinkPath = "Changed"
+
+	assert.NotEqual(t, "Changed", g.Links()[0].LinkPath)
+}
+
+func TestGraph_Copy_IndexNotShared(t *testing.T) {
+	g := graph.NewGraph()
+	toPage := Page()
+	require.NoError(t, g.AddPage(&toPage))
+	assert.Empty(t, g.FindLinksToPage(&toPage))
+
+	fromPage := Page()
+	fromPage.Root.SetProperty("related", "[["+toPage.Name+"]]")
+
+	shallow := g
+	require.NoError(t, shallow.AddPage(&fromPage))
+
+	// Copies share pages, so the original's index is rebuilt to see the new page.
+	assert.Len(t, shallow.FindLinksToPage(&toPage), 1)
+	assert.Len(t, g.FindLinksToPage(&toPage), 1)
+}
+
+func TestGraph_PublicGraph_SharedPageChanged(t *testing.T) {
+	g := graph.NewGraph()
+	fromPage, toPage := Page(), Page()
+	fromPage.Root.SetProperty("public", "true")
+	toPage.Root.SetProperty("public", "true")
+	require.NoError(t, g.AddPage(&fromPage))
+	require.NoError(t, g.AddPage(&toPage))
+
+	publicGraph := g.PublicGraph()
+	assert.Empty(t, g.FindLinksToPage(&toPage))
+	assert.Empty(t, publicGraph.FindLinksToPage(&toPage))
+
+	require.NoError(t, fromPage.Root.Content.SetMarkdown("see [["+toPage.Name+"]]"))
+
+	assert.Len(t, g.FindLinksToPage(&toPage), 1)
+	assert.Len(t, publicGraph.FindLinksToPage(&toPage), 1)
+}
@@ -0,0 +1,49 @@
+// Package graphtest builds small graphs for tests.
+package graphtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+)
+
+// AddPage adds a page to g with the given page properties and a block for each entry in
+// blocks. An entry may hold several lines. Entries starting with "  " become children
+// of the block before them.
+func AddPage(t *testing.T, g *graph.Graph, name string, properties map[string]string, blocks ...string) *graph.Page {
+	t.Helper()
+
+	page := graph.NewEmptyPage()
+	page.Name, page.Title, page.PathInGraph = name, name, "pages/"+name+".md"
+	page.Root.PageName = name
+
+	for propName, propValue := range properties {
+		page.Root.SetProperty(propName, propValue)
+	}
+
+	var parent *graph.Block
+
+	for _, source := range blocks {
+		if strings.HasPrefix(source, "  ") && parent != nil {
+			child, err := graph.NewBlock(&page, strings.Split(source[2:], "\n"), 2)
+			require.NoError(t, err)
+			parent.AddChild(child)
+
+			continue
+		}
+
+		block, err := graph.NewBlock(&page, strings.Split(source, "\n"), 1)
+		require.NoError(t, err)
+		page.Root.AddChild(block)
+
+		parent = block
+	}
+
+	page.SetRoot(page.Root)
+	require.NoError(t, g.AddPage(&page))
+
+	return &page
+}
@@ -0,0 +1,96 @@
+package graph
+
+import "time"
+
+// ChangeType describes how a block differs between two versions of a page.
+type ChangeType string
+
+const (
+	BlockAdded   ChangeType = "added"
+	BlockRemoved ChangeType = "removed"
+	BlockChanged ChangeType = "changed"
+)
+
+// BlockChange is a single block-level difference between two versions of a page.
+type BlockChange struct {
+	Type   ChangeType `json:"type"`
+	Before string     `json:"before,omitempty"`
+	After  string     `json:"after,omitempty"`
+}
+
+// PageVersion is a saved copy of a page, described by how it differs from the version before it.
+type PageVersion struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Source    string        `json:"source"`
+	Changes   []BlockChange `json:"changes"`
+}
+
+// DiffBlocks compares the block contents of two page versions.
+// Blocks are matched by content, and a run of removed blocks followed by added blocks
+// is reported as changed blocks.
+func DiffBlocks(before []string, after []string) []BlockChange {
+	// lcs[i][j] holds the longest common subsequence length of before[i:] and after[j:].
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := []BlockChange{}
+	removed, added := []string{}, []string{}
+
+	flush := func() {
+		changes = append(changes, pairBlockChanges(removed, added)...)
+		removed, added = []string{}, []string{}
+	}
+
+	i, j := 0, 0
+
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			flush()
+
+			i++
+			j++
+		case j < len(after) && (i == len(before) || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, after[j])
+			j++
+		default:
+			removed = append(removed, before[i])
+			i++
+		}
+	}
+
+	flush()
+
+	return changes
+}
+
+// pairBlockChanges turns removed and added blocks found between the same unchanged blocks
+// into changes, pairing them up in order.
+func pairBlockChanges(removed []string, added []string) []BlockChange {
+	changes := []BlockChange{}
+
+	for k := 0; k < max(len(removed), len(added)); k++ {
+		switch {
+		case k < len(removed) && k < len(added):
+			changes = append(changes, BlockChange{Type: BlockChanged, Before: removed[k], After: added[k]})
+		case k < len(removed):
+			changes = append(changes, BlockChange{Type: BlockRemoved, Before: removed[k]})
+		default:
+			changes = append(changes, BlockChange{Type: BlockAdded, After: added[k]})
+		}
+	}
+
+	return changes
+}
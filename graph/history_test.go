@@ -0,0 +1,60 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestHistory_DiffBlocks(t *testing.T) {
+	diffTests := []struct {
+		name   string
+		before []string
+		after  []string
+		want   []graph.BlockChange
+	}{
+		{
+			name:   "unchanged",
+			before: []string{"a", "b"},
+			after:  []string{"a", "b"},
+			want:   []graph.BlockChange{},
+		},
+		{
+			name:   "from nothing",
+			before: []string{},
+			after:  []string{"a"},
+			want:   []graph.BlockChange{{Type: graph.BlockAdded, After: "a"}},
+		},
+		{
+			name:   "added and removed",
+			before: []string{"a", "b", "c"},
+			after:  []string{"a", "c", "d"},
+			want: []graph.BlockChange{
+				{Type: graph.BlockRemoved, Before: "b"},
+				{Type: graph.BlockAdded, After: "d"},
+			},
+		},
+		{
+			name:   "changed",
+			before: []string{"a", "b", "c"},
+			after:  []string{"a", "B", "c"},
+			want:   []graph.BlockChange{{Type: graph.BlockChanged, Before: "b", After: "B"}},
+		},
+		{
+			name:   "changed with extra",
+			before: []string{"a", "b"},
+			after:  []string{"A", "B", "C"},
+			want: []graph.BlockChange{
+				{Type: graph.BlockChanged, Before: "a", After: "A"},
+				{Type: graph.BlockChanged, Before: "b", After: "B"},
+				{Type: graph.BlockAdded, After: "C"},
+			},
+		},
+	}
+
+	for _, tt := range diffTests {
+		assert.Equal(t, tt.want, graph.DiffBlocks(tt.before, tt.after), tt.name)
+	}
+}
@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// graphChanges counts changes to a graph's pages, so its index is rebuilt after them.
+// Copies of a graph share it, since they share pages.
+type graphChanges struct {
+	mu      sync.Mutex    // Guards the indexes of the graphs sharing these pages
+	version atomic.Uint64 // Bumped by each change
+}
+
+// changeWatchers are the graphs holding a page, block content, or property map.
+// A page can be in more than one graph, like the public graph made from it.
+type changeWatchers []*graphChanges
+
+// add starts telling a graph about changes.
+func (w *changeWatchers) add(changes *graphChanges) {
+	if !slices.Contains(*w, changes) {
+		*w = append(*w, changes)
+	}
+}
+
+// notify tells each graph holding the changed value that its index is out of date.
+func (w changeWatchers) notify() {
+	for _, changes := range w {
+		changes.version.Add(1)
+	}
+}
+
+// graphIndex maps link targets and aliases to what points at them, so lookups don't scan
+// every link in the graph.
+type graphIndex struct {
+	owner      *Graph // Copies of a graph build their own index
+	version    uint64 // Change count when the index was built
+	links      []Link
+	inbound    map[string]map[LinkType][]Link // Lowercased page name to the links to it, by type
+	aliases    map[string]*Page               // Lowercased alias to its page
+	blockLinks map[string][]Link              // Block ID to the block references to it
+}
+
+func newGraphIndex(owner *Graph, version uint64) *graphIndex {
+	return &graphIndex{
+		owner:      owner,
+		version:    version,
+		links:      []Link{},
+		inbound:    map[string]map[LinkType][]Link{},
+		aliases:    map[string]*Page{},
+		blockLinks: map[string][]Link{},
+	}
+}
+
+// addPage indexes the links on a page and its aliases.
+func (idx *graphIndex) addPage(page *Page) {
+	for _, alias := range page.Aliases() {
+		aliasKey := strings.ToLower(alias)
+
+		if _, ok := idx.aliases[aliasKey]; !ok {
+			idx.aliases[aliasKey] = page
+		}
+	}
+
+	for _, link := range page.Links() {
+		idx.links = append(idx.links, link)
+
+		if link.LinkType == LinkTypeBlock {
+			idx.blockLinks[link.LinkPath] = append(idx.blockLinks[link.LinkPath], link)
+
+			continue
+		}
+
+		targetKey := strings.ToLower(link.LinkPath)
+		byType, ok := idx.inbound[targetKey]
+
+		if !ok {
+			byType = map[LinkType][]Link{}
+			idx.inbound[targetKey] = byType
+		}
+
+		byType[link.LinkType] = append(byType[link.LinkType], link)
+	}
+}
+
+// withIndex calls lookup with the graph index, building it if the graph changed since it
+// was last used.
+func (g *Graph) withIndex(lookup func(idx *graphIndex)) {
+	if g.changes == nil {
+		lookup(g.buildIndex(0))
+
+		return
+	}
+
+	g.changes.mu.Lock()
+	defer g.changes.mu.Unlock()
+
+	if !g.indexIsCurrent() {
+		g.idx = g.buildIndex(g.changes.version.Load())
+	}
+
+	lookup(g.idx)
+}
+
+// buildIndex indexes every page in the graph.
+func (g *Graph) buildIndex(version uint64) *graphIndex {
+	idx := newGraphIndex(g, version)
+	pageKeys := make([]string, 0, len(g.Pages))
+
+	for pageKey := range g.Pages {
+		pageKeys = append(pageKeys, pageKey)
+	}
+
+	// Index pages in a fixed order, so backlinks are listed the same way every export.
+	sort.Strings(pageKeys)
+
+	for _, pageKey := range pageKeys {
+		idx.addPage(g.Pages[pageKey])
+	}
+
+	return idx
+}
+
+// indexIsCurrent returns true if the graph has its own index, and nothing changed since it was built.
+func (g *Graph) indexIsCurrent() bool {
+	return g.idx != nil && g.idx.owner == g && g.idx.version == g.changes.version.Load()
+}
+
+// watch has a page and its blocks report changes to the graph.
+func (g *Graph) watch(page *Page) {
+	if g.changes == nil {
+		g.changes = &graphChanges{}
+	}
+
+	page.changes.add(g.changes)
+
+	for _, block := range page.AllBlocks {
+		block.watch(page.changes)
+	}
+}
+
+// indexPage records a page added to the graph. Copies of the graph share its pages, so
+// their indexes are rebuilt. The graph's own index gets the page, unless it replaces a
+// page that may be indexed already.
+func (g *Graph) indexPage(page *Page, replacing bool) {
+	g.changes.mu.Lock()
+	defer g.changes.mu.Unlock()
+
+	current := g.indexIsCurrent() && !replacing
+	version := g.changes.version.Add(1)
+
+	if !current {
+		g.idx = nil
+
+		return
+	}
+
+	g.idx.addPage(page)
+	g.idx.version = version
+}
+
+// InvalidateIndex drops the link and alias index, so it's rebuilt on next use.
+// Changes made through Page, Block, BlockContent, and PropertyMap methods do this already.
+func (g *Graph) InvalidateIndex() {
+	if g.changes == nil {
+		return
+	}
+
+	g.changes.mu.Lock()
+	defer g.changes.mu.Unlock()
+
+	g.idx = nil
+}
@@ -18,6 +18,15 @@ type Link struct {
 	LinkType  LinkType `json:"link_type"`
 	IsEmbed   bool     `json:"is_embed"`
 	Label     string   `json:"label"`
+	Labelled  bool     `json:"labelled,omitempty"` // True if the label was written out, like [label]([[page]])
+	Property  string   `json:"property,omitempty"` // Property holding the link, if any
+	Span      *Span    `json:"span,omitempty"`     // Where the link is in its block's Markdown, if it's in the content
+}
+
+// Span is a range of byte offsets in block content.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 // Convenience methods in case I change the implementation details.
@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"regexp"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Link syntax is read by goldmark inline parsers, so code spans, code blocks, raw HTML,
+// and autolinks are skipped the way Markdown skips them.
+var (
+	embedLinkRe    = regexp.MustCompile(`^\{\{embed\s+(?:\[\[(.+?)\]\]|\(\((.+?)\)\))\s*\}\}`)
+	orgLinkRe      = regexp.MustCompile(`^\[\[([^\[\]]+)\]\[([^\[\]]+)\]\]`)
+	orgURLRe       = regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.-]*://|file:)`)
+	pageLinkRe     = regexp.MustCompile(`^\[\[(.+?)\]\]`)
+	labelledLinkRe = regexp.MustCompile(`^\[([^\]]+)\]\((?:\[\[(.+?)\]\]|\(\((.+?)\)\))\)`)
+	assetLinkRe    = regexp.MustCompile(`^(!?)\[([^\]]*)\]\(\.\./assets/([^)]+)\)`)
+	blockLinkRe    = regexp.MustCompile(`^\(\((.+?)\)\)`)
+	tagLinkRe      = regexp.MustCompile(`^#([^\s#,;:!?"'()\[\]{}<>]*[^\s#,.;:!?"'()\[\]{}<>])`)
+	multiWordTagRe = regexp.MustCompile(`^#\[\[(.+?)\]\]`)
+	priorityRe     = regexp.MustCompile(`^#[A-Ca-c]\]`)
+)
+
+var (
+	kindLinkNode = ast.NewNodeKind("LogseqLink")
+	linkParser   = newLinkParser()
+)
+
+// linkNode holds a link found in block content.
+type linkNode struct {
+	ast.BaseInline
+	link Link
+}
+
+func (n *linkNode) Kind() ast.NodeKind {
+	return kindLinkNode
+}
+
+func (n *linkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Raw": n.link.Raw, "LinkPath": n.link.LinkPath}, nil)
+}
+
+// newLinkParser returns a Markdown parser that finds Logseq links and markup along with CommonMark syntax.
+// The link parsers go ahead of goldmark's, which would read [[page]] as a bracketed label.
+func newLinkParser() parser.Parser {
+	return parser.NewParser(
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithInlineParsers(append(append(parser.DefaultInlineParsers(), markupParsers()...),
+			util.Prioritized(&embedLinkParser{}, 50),
+			util.Prioritized(&pageLinkParser{}, 50),
+			util.Prioritized(&blockLinkParser{}, 50),
+			util.Prioritized(&tagLinkParser{}, 50),
+		)...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	)
+}
+
+// FindLinks returns every link written in Markdown content, in source order, with its Span.
+// Links in code aren't included.
+func FindLinks(markdown string) []Link {
+	return parseLinks(markdown)
+}
+
+// parseLinks returns the links in Markdown content, in source order.
+func parseLinks(markdown string) []Link {
+	source := []byte(markdown)
+	document := linkParser.Parse(text.NewReader(source))
+	links := []Link{}
+
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if found, ok := node.(*linkNode); ok && entering {
+			links = append(links, found.link)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return links
+}
+
+// consumeLink advances the reader past a matched link and returns its node.
+func consumeLink(block text.Reader, segment text.Segment, line []byte, length int, link Link) ast.Node {
+	link.Raw = string(line[:length])
+	link.Span = &Span{Start: segment.Start, End: segment.Start + length}
+	block.Advance(length)
+
+	return &linkNode{link: link}
+}
+
+// embedLinkParser reads embed macros like {{embed [[page]]}} and {{embed ((uuid))}}.
+type embedLinkParser struct{}
+
+func (p *embedLinkParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *embedLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	match := embedLinkRe.FindSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	link := Link{LinkPath: string(match[1]), Label: string(match[1]), LinkType: LinkTypePage, IsEmbed: true}
+
+	if len(match[2]) > 0 {
+		link.LinkPath, link.Label, link.LinkType = string(match[2]), string(match[2]), LinkTypeBlock
+	}
+
+	return consumeLink(block, segment, line, len(match[0]), link)
+}
+
+// pageLinkParser reads [[page]] links, labelled links like [label]([[page]]) and
+// [label](((uuid))), labelled Org links like [[page][label]], and Markdown links to assets.
+type pageLinkParser struct{}
+
+func (p *pageLinkParser) Trigger() []byte {
+	return []byte{'[', '!'}
+}
+
+func (p *pageLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	if match := assetLinkRe.FindSubmatch(line); match != nil {
+		link := Link{
+			LinkPath: string(match[3]),
+			Label:    string(match[2]),
+			LinkType: LinkTypeAsset,
+			IsEmbed:  len(match[1]) > 0,
+		}
+
+		return consumeLink(block, segment, line, len(match[0]), link)
+	}
+
+	if match := labelledLinkRe.FindSubmatch(line); match != nil {
+		link := Link{LinkPath: string(match[2]), Label: string(match[1]), LinkType: LinkTypePage, Labelled: true}
+
+		if len(match[3]) > 0 {
+			link.LinkPath, link.LinkType = string(match[3]), LinkTypeBlock
+		}
+
+		return consumeLink(block, segment, line, len(match[0]), link)
+	}
+
+	if match := orgLinkRe.FindSubmatch(line); match != nil {
+		// Org links to URLs aren't page links; keep them as text.
+		if orgURLRe.Match(match[1]) {
+			block.Advance(len(match[0]))
+
+			return ast.NewTextSegment(segment.WithStop(segment.Start + len(match[0])))
+		}
+
+		link := Link{LinkPath: string(match[1]), Label: string(match[2]), LinkType: LinkTypePage, Labelled: true}
+
+		return consumeLink(block, segment, line, len(match[0]), link)
+	}
+
+	if match := pageLinkRe.FindSubmatch(line); match != nil {
+		link := Link{LinkPath: string(match[1]), Label: string(match[1]), LinkType: LinkTypePage}
+
+		return consumeLink(block, segment, line, len(match[0]), link)
+	}
+
+	return nil
+}
+
+// blockLinkParser reads ((uuid)) block references.
+type blockLinkParser struct{}
+
+func (p *blockLinkParser) Trigger() []byte {
+	return []byte{'('}
+}
+
+func (p *blockLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	match := blockLinkRe.FindSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	link := Link{LinkPath: string(match[1]), Label: string(match[1]), LinkType: LinkTypeBlock}
+
+	return consumeLink(block, segment, line, len(match[0]), link)
+}
+
+// tagLinkParser reads #tag and #[[multi word]] tags. Tags may hold any letters or digits,
+// but don't end with punctuation like a period or comma. They must follow whitespace or
+// opening punctuation, so a # inside a word or URL isn't a tag.
+type tagLinkParser struct{}
+
+func (p *tagLinkParser) Trigger() []byte {
+	return []byte{'#'}
+}
+
+func (p *tagLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	preceding := block.PrecendingCharacter()
+	if !isTagBoundary(preceding) {
+		return nil
+	}
+
+	line, segment := block.PeekLine()
+
+	// [#A] is a task priority.
+	if preceding == '[' && priorityRe.Match(line) {
+		return nil
+	}
+
+	match := multiWordTagRe.FindSubmatch(line)
+	if match == nil {
+		match = tagLinkRe.FindSubmatch(line)
+	}
+
+	// #+ starts Org syntax like #+BEGIN_NOTE, not a tag.
+	if match == nil || match[1][0] == '+' {
+		return nil
+	}
+
+	link := Link{LinkPath: string(match[1]), Label: string(match[1]), LinkType: LinkTypeTag}
+
+	return consumeLink(block, segment, line, len(match[0]), link)
+}
+
+// isTagBoundary returns true if a tag may follow the character, like a space or "(".
+func isTagBoundary(r rune) bool {
+	return unicode.IsSpace(r) || unicode.In(r, unicode.Ps, unicode.Pi) || r == '"' || r == '\''
+}
@@ -0,0 +1,260 @@
+package graph
+
+import (
+	"bytes"
+	"regexp"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// MarkupKind says what a piece of Logseq inline markup is.
+type MarkupKind string
+
+const (
+	MarkupMath           MarkupKind = "math"            // $...$
+	MarkupDisplayMath    MarkupKind = "display-math"    // $$...$$
+	MarkupHighlightOpen  MarkupKind = "highlight-open"  // == or ^^, optionally after a color like [[$red]]
+	MarkupHighlightClose MarkupKind = "highlight-close" // The matching == or ^^
+)
+
+// Markup is Logseq inline syntax that CommonMark doesn't know: math, which must be kept
+// as written, and highlights.
+type Markup struct {
+	Kind  MarkupKind
+	Color string // Highlight color, if any
+	Span  Span
+}
+
+var (
+	kindMarkupNode    = ast.NewNodeKind("LogseqMarkup")
+	highlightColorRe  = regexp.MustCompile(`^\[\[\$([a-zA-Z]+)\]\](==|\^\^)`)
+	openHighlightsKey = parser.NewContextKey()
+)
+
+// markupNode holds math or a highlight delimiter found in content.
+type markupNode struct {
+	ast.BaseInline
+	markup Markup
+}
+
+func (n *markupNode) Kind() ast.NodeKind {
+	return kindMarkupNode
+}
+
+func (n *markupNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": string(n.markup.Kind)}, nil)
+}
+
+// MarkupExtender teaches goldmark Logseq math and highlights, rendering math untouched
+// for client side KaTeX or MathJax and highlights as <mark> elements.
+type MarkupExtender struct{}
+
+func (e *MarkupExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(markupParsers()...))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&markupRenderer{}, 100)))
+}
+
+// markupParsers go ahead of link parsers, so [[$red]] isn't read as a page link.
+func markupParsers() []util.PrioritizedValue {
+	return []util.PrioritizedValue{
+		util.Prioritized(&mathParser{}, 40),
+		util.Prioritized(&highlightParser{}, 40),
+	}
+}
+
+// FindMarkup returns the math and highlights in Markdown content, in source order.
+// Markup in code isn't included.
+func FindMarkup(markdown string) []Markup {
+	document := linkParser.Parse(text.NewReader([]byte(markdown)))
+	found := []Markup{}
+
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if markup, ok := node.(*markupNode); ok && entering {
+			found = append(found, markup.markup)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return found
+}
+
+// mathParser reads $inline$ and $$display$$ math. Display math may span lines. Like Pandoc,
+// inline math can't start or end with a space or be followed by a digit, so prices aren't math.
+type mathParser struct{}
+
+func (p *mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	if bytes.HasPrefix(line, []byte("$$")) {
+		return parseDisplayMath(block, segment)
+	}
+
+	for i := 1; i < len(line); i++ {
+		if line[i] != '$' || line[i-1] == '\\' {
+			continue
+		}
+
+		if unicode.IsSpace(rune(line[1])) || unicode.IsSpace(rune(line[i-1])) ||
+			(i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9') {
+			return nil
+		}
+
+		block.Advance(i + 1)
+
+		return &markupNode{markup: Markup{Kind: MarkupMath, Span: Span{Start: segment.Start, End: segment.Start + i + 1}}}
+	}
+
+	return nil
+}
+
+func parseDisplayMath(block text.Reader, start text.Segment) ast.Node {
+	lineNumber, position := block.Position()
+	block.Advance(2)
+
+	for {
+		line, segment := block.PeekLine()
+		if line == nil {
+			block.SetPosition(lineNumber, position)
+
+			return nil
+		}
+
+		if end := bytes.Index(line, []byte("$$")); end >= 0 {
+			block.Advance(end + 2)
+
+			return &markupNode{markup: Markup{Kind: MarkupDisplayMath, Span: Span{Start: start.Start, End: segment.Start + end + 2}}}
+		}
+
+		block.AdvanceLine()
+	}
+}
+
+// highlightParser reads ==highlight== and ^^highlight^^ delimiters, with an optional color
+// like [[$red]]==highlight==. Content between them is parsed as usual, so links are found.
+type highlightParser struct{}
+
+func (p *highlightParser) Trigger() []byte {
+	return []byte{'=', '^', '['}
+}
+
+func (p *highlightParser) Parse(_ ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	color := ""
+	prefix := 0
+
+	if match := highlightColorRe.FindSubmatch(line); match != nil {
+		color = string(match[1])
+		prefix = len(match[0]) - 2
+	}
+
+	delimiter := line[prefix:]
+	if len(delimiter) < 2 || delimiter[0] != delimiter[1] || (delimiter[0] != '=' && delimiter[0] != '^') {
+		return nil
+	}
+
+	open, _ := pc.Get(openHighlightsKey).(map[byte]bool)
+	if open == nil {
+		open = map[byte]bool{}
+		pc.Set(openHighlightsKey, open)
+	}
+
+	char := delimiter[0]
+	markup := Markup{Span: Span{Start: segment.Start, End: segment.Start + prefix + 2}}
+
+	switch {
+	case open[char] && color == "" && !unicode.IsSpace(block.PrecendingCharacter()):
+		markup.Kind = MarkupHighlightClose
+		open[char] = false
+	case !open[char] && len(delimiter) > 2 && !unicode.IsSpace(rune(delimiter[2])) && hasCloser(delimiter[2:], char):
+		markup.Kind, markup.Color = MarkupHighlightOpen, color
+		open[char] = true
+	default:
+		return nil
+	}
+
+	block.Advance(prefix + 2)
+
+	return &markupNode{markup: markup}
+}
+
+// CloseBlock forgets highlights left open at the end of a paragraph.
+func (p *highlightParser) CloseBlock(_ ast.Node, _ text.Reader, pc parser.Context) {
+	pc.Set(openHighlightsKey, nil)
+}
+
+// hasCloser returns true if the rest of a line has a closing highlight delimiter.
+func hasCloser(rest []byte, char byte) bool {
+	delimiter := []byte{char, char}
+
+	for i := bytes.Index(rest, delimiter); i > 0; {
+		if !unicode.IsSpace(rune(rest[i-1])) {
+			return true
+		}
+
+		next := bytes.Index(rest[i+2:], delimiter)
+		if next < 0 {
+			return false
+		}
+
+		i += next + 2
+	}
+
+	return false
+}
+
+// markupRenderer writes math as it was typed, and highlights as <mark> elements.
+type markupRenderer struct{}
+
+func (r *markupRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMarkupNode, r.render)
+}
+
+func (r *markupRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	markup := node.(*markupNode).markup
+
+	switch markup.Kind {
+	case MarkupMath, MarkupDisplayMath:
+		_, _ = w.WriteString(`<span class="` + mathClass(markup.Kind) + `">`)
+		_, _ = w.Write(util.EscapeHTML(source[markup.Span.Start:markup.Span.End]))
+		_, _ = w.WriteString("</span>")
+	case MarkupHighlightOpen:
+		_, _ = w.WriteString(HighlightOpenTag(markup.Color))
+	case MarkupHighlightClose:
+		_, _ = w.WriteString("</mark>")
+	}
+
+	return ast.WalkContinue, nil
+}
+
+// mathClass returns the HTML class for math markup.
+func mathClass(kind MarkupKind) string {
+	if kind == MarkupDisplayMath {
+		return "math display"
+	}
+
+	return "math inline"
+}
+
+// HighlightOpenTag returns the <mark> tag that starts a highlight.
+func HighlightOpenTag(color string) string {
+	if color == "" {
+		return "<mark>"
+	}
+
+	return `<mark class="` + color + `">`
+}
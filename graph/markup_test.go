@@ -0,0 +1,79 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestFindMarkup(t *testing.T) {
+	markupTests := []struct {
+		name     string
+		markdown string
+		want     []graph.Markup
+	}{
+		{
+			"inline math",
+			"Area is $\\pi r_1^2$ here",
+			[]graph.Markup{{Kind: graph.MarkupMath, Span: graph.Span{Start: 8, End: 19}}},
+		},
+		{
+			"display math over lines",
+			"$$\na_1 + b_2\n$$",
+			[]graph.Markup{{Kind: graph.MarkupDisplayMath, Span: graph.Span{Start: 0, End: 15}}},
+		},
+		{
+			"prices aren't math",
+			"It costs $5 or $10",
+			[]graph.Markup{},
+		},
+		{
+			"math in code",
+			"`$x$` and\n```\n==y==\n```",
+			[]graph.Markup{},
+		},
+		{
+			"highlights",
+			"==one== and ^^two^^",
+			[]graph.Markup{
+				{Kind: graph.MarkupHighlightOpen, Span: graph.Span{Start: 0, End: 2}},
+				{Kind: graph.MarkupHighlightClose, Span: graph.Span{Start: 5, End: 7}},
+				{Kind: graph.MarkupHighlightOpen, Span: graph.Span{Start: 12, End: 14}},
+				{Kind: graph.MarkupHighlightClose, Span: graph.Span{Start: 17, End: 19}},
+			},
+		},
+		{
+			"colored highlight",
+			"[[$red]]==urgent==",
+			[]graph.Markup{
+				{Kind: graph.MarkupHighlightOpen, Color: "red", Span: graph.Span{Start: 0, End: 10}},
+				{Kind: graph.MarkupHighlightClose, Span: graph.Span{Start: 16, End: 18}},
+			},
+		},
+		{
+			"comparisons aren't highlights",
+			"if a == b",
+			[]graph.Markup{},
+		},
+	}
+
+	for _, tt := range markupTests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, graph.FindMarkup(tt.markdown))
+		})
+	}
+}
+
+func TestBlockContent_SetMarkdown_MarkupLinks(t *testing.T) {
+	content := graph.NewEmptyBlockContent()
+	err := content.SetMarkdown("$a #b$ and [[$red]]==see [[Page]]==")
+
+	assert.NoError(t, err)
+	assert.Len(t, content.Links, 1)
+
+	_, ok := content.FindLink("Page")
+
+	assert.True(t, ok)
+}
@@ -0,0 +1,216 @@
+package graph
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	orgBlockBeginRe = regexp.MustCompile(`(?i)^\s*#\+BEGIN_(\S+)(?:[ \t]+(.*?))?\s*$`)
+	orgBlockEndRe   = regexp.MustCompile(`(?i)^\s*#\+END_(\S+)\s*$`)
+	codeFenceRe     = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// Org block kinds with special handling. Other kinds hold Markdown like callouts do.
+const (
+	OrgBlockComment = "comment"
+	OrgBlockExample = "example"
+	OrgBlockExport  = "export"
+	OrgBlockQuery   = "query"
+	OrgBlockQuote   = "quote"
+	OrgBlockSource  = "src"
+	OrgBlockCenter  = "center"
+)
+
+// OrgBlock is a #+BEGIN_KIND ... #+END_KIND block in block content, like a NOTE callout
+// or SRC code.
+type OrgBlock struct {
+	Kind    string        `json:"kind"`           // Lowercased, like "note" or "src"
+	Args    string        `json:"args,omitempty"` // What follows the kind, like a SRC language
+	Body    string        `json:"body"`           // Everything between the markers
+	Content []ContentNode `json:"-"`              // Parsed body, unless the block is verbatim
+}
+
+// ContentNode is a piece of block content: Markdown text, or an Org block.
+type ContentNode struct {
+	Text  string
+	Block *OrgBlock
+}
+
+// IsVerbatim returns true if the block body is kept as written instead of holding Markdown
+// and more Org blocks.
+func (ob *OrgBlock) IsVerbatim() bool {
+	switch ob.Kind {
+	case OrgBlockComment, OrgBlockExample, OrgBlockExport, OrgBlockQuery, OrgBlockSource:
+		return true
+	default:
+		return false
+	}
+}
+
+// Language returns the language of a SRC block, or the backend of an EXPORT block.
+func (ob *OrgBlock) Language() string {
+	if fields := strings.Fields(ob.Args); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return ""
+}
+
+// ParseOrgBlocks splits content into Markdown text and Org blocks. Blocks may nest, except
+// inside verbatim blocks. Unclosed or mismatched markers are errors.
+func ParseOrgBlocks(markdown string) ([]ContentNode, error) {
+	nodes, _, err := parseOrgBlocks(markdown)
+
+	return nodes, err
+}
+
+// orgBlockFrame is an Org block being parsed.
+type orgBlockFrame struct {
+	block     *OrgBlock
+	bodyLines []string
+	textLines []string
+}
+
+// parseOrgBlocks parses Org blocks, and also returns the content with verbatim block bodies
+// blanked out, so links can be found without finding the ones in code or comments.
+func parseOrgBlocks(markdown string) ([]ContentNode, string, error) {
+	lines := strings.Split(markdown, "\n")
+	linkLines := make([]string, len(lines))
+	top := &orgBlockFrame{block: &OrgBlock{}}
+	stack := []*orgBlockFrame{top}
+	inFence := false
+
+	for i, line := range lines {
+		linkLines[i] = line
+		current := stack[len(stack)-1]
+
+		if current.block.IsVerbatim() {
+			if match := orgBlockEndRe.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], current.block.Kind) {
+				stack = closeOrgBlock(stack, line)
+
+				continue
+			}
+
+			current.bodyLines = append(current.bodyLines, line)
+			linkLines[i] = strings.Repeat(" ", len(line))
+
+			continue
+		}
+
+		if codeFenceRe.MatchString(line) {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			if match := orgBlockBeginRe.FindStringSubmatch(line); match != nil {
+				current.bodyLines = append(current.bodyLines, line)
+				current.flushText()
+				stack = append(stack, &orgBlockFrame{block: &OrgBlock{Kind: strings.ToLower(match[1]), Args: match[2]}})
+
+				continue
+			}
+
+			if match := orgBlockEndRe.FindStringSubmatch(line); match != nil {
+				kind := strings.ToLower(match[1])
+
+				if len(stack) == 1 {
+					return nil, "", errors.Errorf("#+END_%s without #+BEGIN_%s", match[1], match[1])
+				}
+
+				if kind != current.block.Kind {
+					return nil, "", errors.Errorf("#+BEGIN_%s closed by #+END_%s", strings.ToUpper(current.block.Kind), match[1])
+				}
+
+				stack = closeOrgBlock(stack, line)
+
+				continue
+			}
+		}
+
+		current.bodyLines = append(current.bodyLines, line)
+		current.textLines = append(current.textLines, line)
+	}
+
+	if len(stack) > 1 {
+		kind := strings.ToUpper(stack[len(stack)-1].block.Kind)
+
+		return nil, "", errors.Errorf("#+BEGIN_%s without #+END_%s", kind, kind)
+	}
+
+	top.flushText()
+
+	return top.block.Content, strings.Join(linkLines, "\n"), nil
+}
+
+// flushText adds the text lines seen since the last Org block as a text node.
+func (f *orgBlockFrame) flushText() {
+	if len(f.textLines) > 0 {
+		f.block.Content = append(f.block.Content, ContentNode{Text: strings.Join(f.textLines, "\n")})
+		f.textLines = nil
+	}
+}
+
+// closeOrgBlock finishes the innermost Org block at its end line and adds it to its parent.
+func closeOrgBlock(stack []*orgBlockFrame, endLine string) []*orgBlockFrame {
+	closed := stack[len(stack)-1]
+	stack = stack[:len(stack)-1]
+	parent := stack[len(stack)-1]
+
+	closed.flushText()
+	closed.block.Body = strings.Join(closed.bodyLines, "\n")
+
+	if closed.block.IsVerbatim() {
+		closed.block.Content = nil
+	}
+
+	parent.bodyLines = append(parent.bodyLines, closed.bodyLines...)
+	parent.bodyLines = append(parent.bodyLines, endLine)
+	parent.block.Content = append(parent.block.Content, ContentNode{Block: closed.block})
+
+	return stack
+}
+
+// OrgBlocks returns the Org blocks at the top level of content nodes.
+func OrgBlocks(nodes []ContentNode) []OrgBlock {
+	blocks := []OrgBlock{}
+
+	for _, node := range nodes {
+		if node.Block != nil {
+			blocks = append(blocks, *node.Block)
+		}
+	}
+
+	return blocks
+}
+
+// StripOrgBlocks returns content without Org block markers, for showing it as plain text.
+// Block bodies are kept, except for comments and queries.
+func StripOrgBlocks(markdown string) string {
+	nodes, err := ParseOrgBlocks(markdown)
+	if err != nil {
+		return markdown
+	}
+
+	return strings.Join(plainText(nodes), "\n")
+}
+
+func plainText(nodes []ContentNode) []string {
+	lines := []string{}
+
+	for _, node := range nodes {
+		switch {
+		case node.Block == nil:
+			lines = append(lines, node.Text)
+		case node.Block.Kind == OrgBlockComment || node.Block.Kind == OrgBlockQuery:
+		case node.Block.IsVerbatim():
+			lines = append(lines, node.Block.Body)
+		default:
+			lines = append(lines, plainText(node.Block.Content)...)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestParseOrgBlocks(t *testing.T) {
+	nodes, err := graph.ParseOrgBlocks("Intro\n#+BEGIN_QUOTE\nQuoted\n#+BEGIN_TIP\nNested\n#+END_TIP\n#+END_QUOTE\nOutro")
+
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 3)
+	assert.Equal(t, "Intro", nodes[0].Text)
+	assert.Equal(t, "Outro", nodes[2].Text)
+
+	quote := nodes[1].Block
+
+	assert.Equal(t, graph.OrgBlockQuote, quote.Kind)
+	assert.Equal(t, "Quoted\n#+BEGIN_TIP\nNested\n#+END_TIP", quote.Body)
+	assert.Len(t, quote.Content, 2)
+	assert.Equal(t, "Quoted", quote.Content[0].Text)
+	assert.Equal(t, "tip", quote.Content[1].Block.Kind)
+	assert.Equal(t, "Nested", quote.Content[1].Block.Body)
+}
+
+func TestParseOrgBlocks_Verbatim(t *testing.T) {
+	nodes, err := graph.ParseOrgBlocks("#+begin_src org\n#+BEGIN_NOTE\n#+end_src")
+
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, graph.OrgBlockSource, nodes[0].Block.Kind)
+	assert.Equal(t, "org", nodes[0].Block.Language())
+	assert.Equal(t, "#+BEGIN_NOTE", nodes[0].Block.Body)
+	assert.Empty(t, nodes[0].Block.Content)
+}
+
+func TestParseOrgBlocks_CodeFence(t *testing.T) {
+	nodes, err := graph.ParseOrgBlocks("```\n#+END_NOTE\n```")
+
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 1)
+	assert.Nil(t, nodes[0].Block)
+}
+
+func TestParseOrgBlocks_Errors(t *testing.T) {
+	errorTests := []struct {
+		markdown string
+		want     string
+	}{
+		{"#+BEGIN_NOTE\ntext", "#+BEGIN_NOTE without #+END_NOTE"},
+		{"text\n#+END_NOTE", "#+END_NOTE without #+BEGIN_NOTE"},
+		{"#+BEGIN_QUOTE\n#+BEGIN_NOTE\n#+END_QUOTE", "#+BEGIN_NOTE closed by #+END_QUOTE"},
+	}
+
+	for _, tt := range errorTests {
+		_, err := graph.ParseOrgBlocks(tt.markdown)
+
+		assert.ErrorContains(t, err, tt.want)
+	}
+}
+
+func TestStripOrgBlocks(t *testing.T) {
+	stripped := graph.StripOrgBlocks("Plan\n#+BEGIN_COMMENT\nsecret\n#+END_COMMENT\n#+BEGIN_NOTE\nRemember\n#+END_NOTE")
+
+	assert.Equal(t, "Plan\nRemember", stripped)
+}
@@ -3,18 +3,25 @@ package graph
 import (
 	"fmt"
 	"regexp"
+	"time"
 )
 
 type Page struct {
-	Name        string   `json:"-"`
-	Title       string   `json:"title"`
-	Namespace   string   `json:"namespace"`
-	Path        string   `json:"path"`
-	PathInGraph string   `json:"path_in_graph"`
-	Root        *Block   `json:"root"`
-	AllBlocks   []*Block `json:"-"`
-	Backlinks   []Link   `json:"backlinks"`
-	TaggedLinks []Link   `json:"tag_links"`
+	Name        string         `json:"-"`
+	Title       string         `json:"title"`
+	Namespace   string         `json:"namespace"`
+	Path        string         `json:"path"`
+	PathInGraph string         `json:"path_in_graph"`
+	JournalDay  string         `json:"journal_day,omitempty"`
+	CreatedAt   *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+	Whiteboard  *Whiteboard    `json:"whiteboard,omitempty"`
+	History     []PageVersion  `json:"history,omitempty"`
+	Root        *Block         `json:"root"`
+	AllBlocks   []*Block       `json:"-"`
+	Backlinks   []Link         `json:"backlinks"`
+	TaggedLinks []Link         `json:"tag_links"`
+	changes     changeWatchers // Graphs holding the page
 }
 
 func NewEmptyPage() Page {
@@ -38,13 +45,51 @@ func (p *Page) Aliases() []string {
 	return aliasesProp.List()
 }
 
-// IsJournal returns true if the page name looks like a journal entry.
+// BlockContents returns the Markdown content of each block in the page, in page order.
+// Blocks without content are left out. For public pages, so are blocks marked private.
+// The caller says whether the page is public, since an old copy may predate its public:: flag.
+func (p *Page) BlockContents(publicPage bool) []string {
+	contents := []string{}
+
+	for _, block := range p.AllBlocks {
+		if block.Content.Markdown == "" || (publicPage && block.IsMarkedPrivate()) {
+			continue
+		}
+
+		contents = append(contents, block.Content.Markdown)
+	}
+
+	return contents
+}
+
+// IsJournal returns true if the page was loaded as a journal or its name looks like one.
 func (p *Page) IsJournal() bool {
+	if p.JournalDay != "" {
+		return true
+	}
+
 	dateRe := regexp.MustCompile(`^\d{4}[/-]\d{2}[/-]\d{2}$`)
 
 	return dateRe.MatchString(p.Name)
 }
 
+// IsWhiteboard returns true if the page was loaded from a whiteboard.
+func (p *Page) IsWhiteboard() bool {
+	return p.Whiteboard != nil
+}
+
+// SetTimestamps records when the page was created and last updated.
+// A zero time leaves the existing value alone.
+func (p *Page) SetTimestamps(created time.Time, updated time.Time) {
+	if !created.IsZero() {
+		p.CreatedAt = &created
+	}
+
+	if !updated.IsZero() {
+		p.UpdatedAt = &updated
+	}
+}
+
 // IsPlaceholder returns true if the page is not a file on disk.
 func (p *Page) IsPlaceholder() bool {
 	return p.PathInGraph == ""
@@ -118,6 +163,9 @@ func (p *Page) SetRoot(root *Block) {
 
 // AddTree adds a block and its children to the page's AllBlocks.
 func (p *Page) AddTree(block *Block) {
+	block.watch(p.changes)
+	p.changes.notify()
+
 	p.AllBlocks = append(p.AllBlocks, block)
 	for _, child := range block.Children {
 		p.AddTree(child)
@@ -130,6 +178,9 @@ func (p *Page) AddPublicTree(block *Block) {
 		return
 	}
 
+	block.watch(p.changes)
+	p.changes.notify()
+
 	p.AllBlocks = append(p.AllBlocks, block)
 
 	for _, child := range block.Children {
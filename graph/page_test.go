@@ -2,6 +2,7 @@ package graph_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/assert"
@@ -166,3 +167,43 @@ func TestPage_Tags(t *testing.T) {
 
 	assert.Contains(t, tags, tag)
 }
+
+func TestPage_SetTimestamps(t *testing.T) {
+	page := graph.NewEmptyPage()
+	created := gofakeit.Date()
+	updated := gofakeit.Date()
+
+	page.SetTimestamps(created, time.Time{})
+	assert.Equal(t, created, *page.CreatedAt)
+	assert.Nil(t, page.UpdatedAt)
+
+	page.SetTimestamps(time.Time{}, updated)
+	assert.Equal(t, created, *page.CreatedAt)
+	assert.Equal(t, updated, *page.UpdatedAt)
+}
+
+func TestPage_BlockContents(t *testing.T) {
+	page := graph.NewEmptyPage()
+	page.Root.Properties.Set("public", "true")
+
+	shared, _ := graph.NewBlock(&page, []string{"shared"}, 1)
+	private, _ := graph.NewBlock(&page, []string{"private", "public:: false"}, 1)
+	page.Root.AddChild(shared)
+	page.Root.AddChild(private)
+	page.SetRoot(page.Root)
+
+	assert.Equal(t, []string{"shared"}, page.BlockContents(true))
+	assert.Equal(t, []string{"shared", "private"}, page.BlockContents(false))
+}
+
+func TestPage_BlockContents_PrivateParent(t *testing.T) {
+	page := graph.NewEmptyPage()
+
+	parent, _ := graph.NewBlock(&page, []string{"parent", "public:: false"}, 1)
+	child, _ := graph.NewBlock(&page, []string{"child"}, 2)
+	parent.AddChild(child)
+	page.Root.AddChild(parent)
+	page.SetRoot(page.Root)
+
+	assert.Empty(t, page.BlockContents(true))
+}
@@ -2,14 +2,16 @@ package graph
 
 import (
 	"encoding/json"
-
+	"regexp"
 	"strings"
+	"time"
 )
 
 // Property represents a property of a block in a Logseq graph.
 type Property struct {
 	Name  string
 	Value string
+	Typed PropertyValue // Value parsed into its type when the property is set
 }
 
 // String returns the value of the property as a string.
@@ -22,15 +24,61 @@ func (p *Property) String() string {
 	return p.Value
 }
 
+// Parsed returns the value of the property parsed into its type.
+func (p *Property) Parsed() PropertyValue {
+	if p.Typed.Type == "" {
+		return ParsePropertyValue(p.Name, p.Value)
+	}
+
+	return p.Typed
+}
+
 // Bool returns the value of the property interpreted as a boolean.
 func (p *Property) Bool() bool {
-	return p.Value == "true"
+	value := p.Parsed()
+
+	return value.Type == PropertyTypeBool && value.Value == true
+}
+
+// Number returns the value of the property as a number, if it is one.
+func (p *Property) Number() (float64, bool) {
+	number, ok := p.Parsed().Value.(float64)
+
+	return number, ok
+}
+
+// Date returns the value of the property as a date, if it is one.
+func (p *Property) Date() (time.Time, bool) {
+	date, ok := p.Parsed().Value.(time.Time)
+
+	return date, ok
 }
 
 // List returns the value of the property as a list of strings.
-// The value is split by commas and spaces.
+// The value is split by commas outside of page links, and link syntax is removed from each item.
 func (p *Property) List() []string {
-	return strings.Split(p.Value, ", ")
+	list := []string{}
+
+	for _, item := range splitPropertyList(p.Value) {
+		list = append(list, parsePropertyScalar(item).String())
+	}
+
+	return list
+}
+
+// Page references and tags anywhere in a property value.
+var propertyRefRe = regexp.MustCompile(`\[\[([^\[\]]+)\]\]|(?:^|[\s,])#(?:\[\[([^\[\]]+)\]\]|([^\s#,\[\]]+))`)
+
+// PageRefs returns the names of pages referenced by page links or tags in the property value,
+// in the order they appear.
+func (p *Property) PageRefs() []string {
+	refs := []string{}
+
+	for _, match := range propertyRefRe.FindAllStringSubmatch(p.Value, -1) {
+		refs = append(refs, match[1]+match[2]+match[3])
+	}
+
+	return refs
 }
 
 // IsPageLink returns true if the value of the property is a page link.
@@ -40,6 +88,7 @@ func (p *Property) IsPageLink() bool {
 
 type PropertyMap struct {
 	Properties map[string]Property
+	changes    changeWatchers // Graphs holding the block with these properties
 }
 
 func NewPropertyMap() *PropertyMap {
@@ -63,13 +112,17 @@ func (pm *PropertyMap) Set(name string, value string) {
 	pm.Properties[name] = Property{
 		Name:  name,
 		Value: strings.TrimSpace(value),
+		Typed: ParsePropertyValue(name, value),
 	}
+
+	pm.changes.notify()
 }
 
+// MarshalJSON encodes each property as its typed value, keeping the original text.
 func (pm *PropertyMap) MarshalJSON() ([]byte, error) {
-	propsMap := map[string]string{}
+	propsMap := map[string]PropertyValue{}
 	for name, prop := range pm.Properties {
-		propsMap[name] = prop.String()
+		propsMap[name] = prop.Parsed()
 	}
 
 	return json.Marshal(&propsMap)
@@ -16,6 +16,8 @@ func TestProperty_String(t *testing.T) {
 		{"value", "value"},
 		{"", ""},
 		{"[[value]]", "value"},
+		{`"quoted"`, `"quoted"`},
+		{"#tag", "#tag"},
 	}
 
 	for _, tt := range stringTests {
@@ -58,6 +60,45 @@ func TestProperty_List(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestProperty_List_PageLinks(t *testing.T) {
+	prop := graph.Property{
+		Name:  "tags",
+		Value: "[[a, b]], #c, d",
+	}
+	want := []string{"a, b", "c", "d"}
+	got := prop.List()
+	assert.Equal(t, want, got)
+}
+
+func TestProperty_Number(t *testing.T) {
+	prop := graph.Property{Name: "test", Value: "3.5"}
+	number, ok := prop.Number()
+	assert.True(t, ok)
+	assert.InDelta(t, 3.5, number, 0)
+
+	prop = graph.Property{Name: "test", Value: "three"}
+	_, ok = prop.Number()
+	assert.False(t, ok)
+}
+
+func TestProperty_Date(t *testing.T) {
+	prop := graph.Property{Name: "test", Value: "2024-06-03"}
+	date, ok := prop.Date()
+	assert.True(t, ok)
+	assert.Equal(t, 2024, date.Year())
+}
+
+func TestProperty_PageRefs(t *testing.T) {
+	prop := graph.Property{Name: "test", Value: "[[a]], #b"}
+	assert.Equal(t, []string{"a", "b"}, prop.PageRefs())
+
+	prop = graph.Property{Name: "test", Value: "see [[a]] and #[[b c]], not https://example.com/#d"}
+	assert.Equal(t, []string{"a", "b c"}, prop.PageRefs())
+
+	prop = graph.Property{Name: "test", Value: "plain"}
+	assert.Empty(t, prop.PageRefs())
+}
+
 func TestProperty_IsPageLink(t *testing.T) {
 	pageLinkTests := []struct {
 		value string
@@ -106,3 +147,13 @@ func TestPropertyMap_Set(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, propValue, prop.Value)
 }
+
+func TestPropertyMap_Set_ParsesValue(t *testing.T) {
+	pm := graph.NewPropertyMap()
+	pm.Set("rating", "4.5")
+
+	prop, _ := pm.Get("rating")
+	assert.Equal(t, graph.PropertyTypeNumber, prop.Typed.Type)
+	assert.Equal(t, 4.5, prop.Typed.Value)
+	assert.Equal(t, prop.Typed, prop.Parsed())
+}
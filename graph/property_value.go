@@ -0,0 +1,177 @@
+package graph
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertyType identifies the kind of value a property holds.
+type PropertyType string
+
+const (
+	PropertyTypeString  PropertyType = "string"
+	PropertyTypeNumber  PropertyType = "number"
+	PropertyTypeBool    PropertyType = "boolean"
+	PropertyTypeDate    PropertyType = "date"
+	PropertyTypePageRef PropertyType = "page_ref"
+	PropertyTypeList    PropertyType = "list"
+)
+
+// Properties that Logseq always treats as comma separated lists.
+var listPropertyNames = map[string]bool{
+	"alias": true,
+	"tags":  true,
+}
+
+// Date layouts recognized in property values.
+var propertyDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+var (
+	propertyNumberRe  = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	propertyPageRefRe = regexp.MustCompile(`^\[\[([^\[\]]+)\]\]$`)
+	propertyTagRe     = regexp.MustCompile(`^#(?:\[\[([^\[\]]+)\]\]|([^\s#,\[\]]+))$`)
+)
+
+// PropertyValue is a property value parsed into its type.
+// Text keeps the value as it was written, and Value holds a string, float64, bool,
+// time.Time, page name, or a []PropertyValue for lists.
+type PropertyValue struct {
+	Type  PropertyType `json:"type"`
+	Text  string       `json:"text"`
+	Value any          `json:"value"`
+}
+
+// ParsePropertyValue determines the type of a property value.
+// Values are split into lists when the property is always a list, or when every
+// comma separated item is a page reference or tag.
+func ParsePropertyValue(name string, text string) PropertyValue {
+	text = strings.TrimSpace(text)
+	items := splitPropertyList(text)
+
+	if len(items) > 1 || listPropertyNames[name] {
+		values := []PropertyValue{}
+		allRefs := true
+
+		for _, item := range items {
+			value := parsePropertyScalar(item)
+			allRefs = allRefs && value.Type == PropertyTypePageRef
+			values = append(values, value)
+		}
+
+		if listPropertyNames[name] || (len(values) > 1 && allRefs) {
+			return PropertyValue{Type: PropertyTypeList, Text: text, Value: values}
+		}
+	}
+
+	return parsePropertyScalar(text)
+}
+
+// parsePropertyScalar determines the type of a single property value.
+func parsePropertyScalar(text string) PropertyValue {
+	value := PropertyValue{Type: PropertyTypeString, Text: text, Value: text}
+
+	if len(text) >= 2 && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) {
+		value.Value = text[1 : len(text)-1]
+
+		return value
+	}
+
+	if match := propertyPageRefRe.FindStringSubmatch(text); match != nil {
+		value.Type, value.Value = PropertyTypePageRef, match[1]
+
+		return value
+	}
+
+	if match := propertyTagRe.FindStringSubmatch(text); match != nil {
+		value.Type, value.Value = PropertyTypePageRef, match[1]+match[2]
+
+		return value
+	}
+
+	switch strings.ToLower(text) {
+	case "true":
+		value.Type, value.Value = PropertyTypeBool, true
+
+		return value
+	case "false":
+		value.Type, value.Value = PropertyTypeBool, false
+
+		return value
+	}
+
+	if propertyNumberRe.MatchString(text) {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			value.Type, value.Value = PropertyTypeNumber, number
+
+			return value
+		}
+	}
+
+	for _, layout := range propertyDateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			value.Type, value.Value = PropertyTypeDate, date
+
+			return value
+		}
+	}
+
+	return value
+}
+
+// splitPropertyList splits a value on commas that aren't inside a page reference,
+// leaving out empty items.
+func splitPropertyList(text string) []string {
+	items := []string{}
+	depth := 0
+	start := 0
+
+	addItem := func(item string) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "[["):
+			depth++
+			i++
+		case strings.HasPrefix(text[i:], "]]") && depth > 0:
+			depth--
+			i++
+		case text[i] == ',' && depth == 0:
+			addItem(text[start:i])
+			start = i + 1
+		}
+	}
+
+	addItem(text[start:])
+
+	return items
+}
+
+// String returns the value as display text, with page reference syntax removed.
+func (v PropertyValue) String() string {
+	if text, ok := v.Value.(string); ok {
+		return text
+	}
+
+	return v.Text
+}
+
+// Items returns the values in a list, or the value itself if it isn't a list.
+func (v PropertyValue) Items() []PropertyValue {
+	if items, ok := v.Value.([]PropertyValue); ok {
+		return items
+	}
+
+	return []PropertyValue{v}
+}
@@ -0,0 +1,82 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+)
+
+func TestPropertyValue_ParsePropertyValue(t *testing.T) {
+	parseTests := []struct {
+		name     string
+		text     string
+		wantType graph.PropertyType
+		want     any
+	}{
+		{"status", "draft", graph.PropertyTypeString, "draft"},
+		{"title", "Hello, World", graph.PropertyTypeString, "Hello, World"},
+		{"quote", `"true, 42"`, graph.PropertyTypeString, "true, 42"},
+		{"rating", "42", graph.PropertyTypeNumber, 42.0},
+		{"ratio", "-0.5", graph.PropertyTypeNumber, -0.5},
+		{"version", "1.2.3", graph.PropertyTypeString, "1.2.3"},
+		{"public", "true", graph.PropertyTypeBool, true},
+		{"public", "False", graph.PropertyTypeBool, false},
+		{"date", "2024-06-03", graph.PropertyTypeDate, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"date", "2024/06/03", graph.PropertyTypeDate, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"source", "[[Some Page]]", graph.PropertyTypePageRef, "Some Page"},
+		{"category", "#research", graph.PropertyTypePageRef, "research"},
+		{"category", "#[[deep research]]", graph.PropertyTypePageRef, "deep research"},
+	}
+
+	for _, tt := range parseTests {
+		value := graph.ParsePropertyValue(tt.name, tt.text)
+
+		assert.Equal(t, tt.wantType, value.Type, tt.text)
+		assert.Equal(t, tt.want, value.Value, tt.text)
+		assert.Equal(t, tt.text, value.Text, tt.text)
+	}
+}
+
+func TestPropertyValue_ParsePropertyValue_Lists(t *testing.T) {
+	value := graph.ParsePropertyValue("related", "[[a, b]], #c, [[d]]")
+
+	require.Equal(t, graph.PropertyTypeList, value.Type)
+
+	names := []string{}
+	for _, item := range value.Items() {
+		assert.Equal(t, graph.PropertyTypePageRef, item.Type)
+		names = append(names, item.String())
+	}
+
+	assert.Equal(t, []string{"a, b", "c", "d"}, names)
+
+	tags := graph.ParsePropertyValue("tags", "plain")
+	require.Equal(t, graph.PropertyTypeList, tags.Type)
+	assert.Equal(t, "plain", tags.Items()[0].String())
+
+	mixed := graph.ParsePropertyValue("note", "[[a]], and more")
+	assert.Equal(t, graph.PropertyTypeString, mixed.Type)
+}
+
+func TestPropertyMap_MarshalJSON(t *testing.T) {
+	pm := graph.NewPropertyMap()
+	pm.Set("rating", "4")
+	pm.Set("tags", "[[a]], b")
+
+	encoded, err := json.Marshal(pm)
+	require.NoError(t, err)
+
+	want := `{
+		"rating": {"type": "number", "text": "4", "value": 4},
+		"tags": {"type": "list", "text": "[[a]], b", "value": [
+			{"type": "page_ref", "text": "[[a]]", "value": "a"},
+			{"type": "string", "text": "b", "value": "b"}
+		]}
+	}`
+	assert.JSONEq(t, want, string(encoded))
+}
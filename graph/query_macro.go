@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	queryMacroRe       = regexp.MustCompile(`^\{\{query\s+((?:[^}]|\}[^}])*)\}\}`)
+	kindQueryMacroNode = ast.NewNodeKind("LogseqQueryMacro")
+	queryMacroParser   = parser.NewParser(
+		parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+		parser.WithInlineParsers(append(parser.DefaultInlineParsers(), util.Prioritized(&queryMacroInlineParser{}, 50))...),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+	)
+)
+
+// QueryMacro is a {{query ...}} simple query in block content.
+type QueryMacro struct {
+	Text string // The query, without the macro braces
+	Span Span
+}
+
+// queryMacroNode holds a query macro found in content.
+type queryMacroNode struct {
+	ast.BaseInline
+	macro QueryMacro
+}
+
+func (n *queryMacroNode) Kind() ast.NodeKind {
+	return kindQueryMacroNode
+}
+
+func (n *queryMacroNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Text": n.macro.Text}, nil)
+}
+
+// FindQueryMacros returns the {{query}} macros in Markdown content, in source order.
+// Macros in code aren't included.
+func FindQueryMacros(markdown string) []QueryMacro {
+	document := queryMacroParser.Parse(text.NewReader([]byte(markdown)))
+	found := []QueryMacro{}
+
+	_ = ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if macro, ok := node.(*queryMacroNode); ok && entering {
+			found = append(found, macro.macro)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return found
+}
+
+// queryMacroInlineParser reads {{query ...}} macros. It has its own Markdown parser,
+// so links inside queries are still found by the link parser.
+type queryMacroInlineParser struct{}
+
+func (p *queryMacroInlineParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *queryMacroInlineParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	match := queryMacroRe.FindSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	block.Advance(len(match[0]))
+
+	return &queryMacroNode{macro: QueryMacro{
+		Text: string(match[1]),
+		Span: Span{Start: segment.Start, End: segment.Start + len(match[0])},
+	}}
+}
@@ -0,0 +1,53 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestFindQueryMacros(t *testing.T) {
+	macroTests := []struct {
+		name     string
+		markdown string
+		want     []graph.QueryMacro
+	}{
+		{
+			name:     "simple query",
+			markdown: "Open: {{query (task TODO)}}",
+			want:     []graph.QueryMacro{{Text: "(task TODO)", Span: graph.Span{Start: 6, End: 27}}},
+		},
+		{
+			name:     "query with page link",
+			markdown: "{{query [[Project]]}} and {{query \"text\"}}",
+			want: []graph.QueryMacro{
+				{Text: "[[Project]]", Span: graph.Span{Start: 0, End: 21}},
+				{Text: "\"text\"", Span: graph.Span{Start: 26, End: 42}},
+			},
+		},
+		{
+			name:     "code span",
+			markdown: "Write `{{query (task TODO)}}` for tasks",
+			want:     []graph.QueryMacro{},
+		},
+		{
+			name:     "fenced code",
+			markdown: "Example:\n```\n{{query (task TODO)}}\n```",
+			want:     []graph.QueryMacro{},
+		},
+	}
+
+	for _, tt := range macroTests {
+		assert.Equal(t, tt.want, graph.FindQueryMacros(tt.markdown), tt.name)
+	}
+}
+
+func TestBlockContent_SetMarkdown_QueryLinks(t *testing.T) {
+	bc := BlockContent()
+	assert.NoError(t, bc.SetMarkdown("{{query [[Project]]}}"))
+
+	_, ok := bc.FindLink("Project")
+	assert.True(t, ok, "links inside queries are still found")
+}
@@ -0,0 +1,279 @@
+package graph
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TaskMarker is the workflow keyword that starts a task block.
+type TaskMarker string
+
+const (
+	TaskMarkerTodo     TaskMarker = "TODO"
+	TaskMarkerDoing    TaskMarker = "DOING"
+	TaskMarkerNow      TaskMarker = "NOW"
+	TaskMarkerLater    TaskMarker = "LATER"
+	TaskMarkerDone     TaskMarker = "DONE"
+	TaskMarkerCanceled TaskMarker = "CANCELED"
+	TaskMarkerWaiting  TaskMarker = "WAITING"
+)
+
+// Logseq accepts a few spellings of some markers.
+var taskMarkerAliases = map[string]TaskMarker{
+	"CANCELLED":   TaskMarkerCanceled,
+	"IN-PROGRESS": TaskMarkerDoing,
+	"WAIT":        TaskMarkerWaiting,
+}
+
+var (
+	taskMarkerRe    = regexp.MustCompile(`^(TODO|DOING|NOW|LATER|DONE|CANCELED|CANCELLED|WAITING|WAIT|IN-PROGRESS)(?:\s+|$)`)
+	taskPriorityRe  = regexp.MustCompile(`^\[#([A-Z])\]\s*`)
+	taskPlanningRe  = regexp.MustCompile(`(SCHEDULED|DEADLINE):\s*<([^>]+)>`)
+	taskTimestampRe = regexp.MustCompile(
+		`^(\d{4}-\d{2}-\d{2})(?:\s+[A-Za-z]+)?(?:\s+(\d{1,2}:\d{2}))?(?:\s+((?:\.\+|\+\+|\+)\d+[hdwmy]))?$`,
+	)
+	clockRe = regexp.MustCompile(
+		`^CLOCK:\s*\[([^\]]+)\](?:--\[([^\]]+)\])?`,
+	)
+	clockTimeRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:\s+[A-Za-z]+)?\s+(\d{1,2}:\d{2}(?::\d{2})?)$`)
+)
+
+const (
+	logbookStart = ":LOGBOOK:"
+	drawerEnd    = ":END:"
+)
+
+// TaskTimestamp is a SCHEDULED or DEADLINE date, optionally with a time of day and a repeater
+// like "+1w" (every week), "++1w" (next future week) or ".+1w" (a week after completion).
+type TaskTimestamp struct {
+	Time     time.Time `json:"time"`
+	HasTime  bool      `json:"has_time,omitempty"`
+	Repeater string    `json:"repeater,omitempty"`
+}
+
+// ClockEntry is a period of time logged against a task. Running clocks have no end.
+type ClockEntry struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// Duration returns how long the clock ran, or zero for a running clock.
+func (c ClockEntry) Duration() time.Duration {
+	if c.End == nil {
+		return 0
+	}
+
+	return c.End.Sub(c.Start)
+}
+
+// Task holds the workflow details of a task block.
+type Task struct {
+	Marker    TaskMarker     `json:"marker"`
+	Priority  string         `json:"priority,omitempty"`
+	Scheduled *TaskTimestamp `json:"scheduled,omitempty"`
+	Deadline  *TaskTimestamp `json:"deadline,omitempty"`
+	Clock     []ClockEntry   `json:"clock,omitempty"`
+}
+
+// ParseTask reads task details from block content, returning nil if the block isn't a task.
+// Logseq writes times without a zone, so they're read in location.
+func ParseTask(markdown string, location *time.Location) *Task {
+	match := taskMarkerRe.FindStringSubmatch(markdown)
+	if match == nil {
+		return nil
+	}
+
+	marker := TaskMarker(match[1])
+	if alias, ok := taskMarkerAliases[match[1]]; ok {
+		marker = alias
+	}
+
+	task := Task{Marker: marker}
+
+	if priority := taskPriorityRe.FindStringSubmatch(markdown[len(match[0]):]); priority != nil {
+		task.Priority = priority[1]
+	}
+
+	task.Scheduled, task.Deadline = ParsePlanning(markdown, location)
+	task.Clock = ParseClockEntries(markdown, location)
+
+	return &task
+}
+
+// ParsePlanning reads the SCHEDULED and DEADLINE timestamps from block content.
+// Either is nil when the block doesn't have one.
+func ParsePlanning(markdown string, location *time.Location) (*TaskTimestamp, *TaskTimestamp) {
+	var scheduled, deadline *TaskTimestamp
+
+	for _, planning := range taskPlanningRe.FindAllStringSubmatch(markdown, -1) {
+		timestamp, ok := parseTaskTimestamp(planning[2], location)
+		if !ok {
+			continue
+		}
+
+		if planning[1] == "SCHEDULED" {
+			scheduled = &timestamp
+		} else {
+			deadline = &timestamp
+		}
+	}
+
+	return scheduled, deadline
+}
+
+// IsDone returns true if the task was completed.
+func (t *Task) IsDone() bool {
+	return t.Marker == TaskMarkerDone
+}
+
+// IsClosed returns true if nothing more will happen with the task.
+func (t *Task) IsClosed() bool {
+	return t.Marker == TaskMarkerDone || t.Marker == TaskMarkerCanceled
+}
+
+// ClockedTime returns the total time logged against the task.
+func (t *Task) ClockedTime() time.Duration {
+	total := time.Duration(0)
+
+	for _, entry := range t.Clock {
+		total += entry.Duration()
+	}
+
+	return total
+}
+
+func parseTaskTimestamp(text string, location *time.Location) (TaskTimestamp, bool) {
+	match := taskTimestampRe.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return TaskTimestamp{}, false
+	}
+
+	layout, value := "2006-01-02", match[1]
+	if match[2] != "" {
+		layout, value = "2006-01-02 15:04", match[1]+" "+match[2]
+	}
+
+	parsed, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return TaskTimestamp{}, false
+	}
+
+	return TaskTimestamp{Time: parsed, HasTime: match[2] != "", Repeater: match[3]}, true
+}
+
+// ParseClockEntries reads CLOCK lines from the :LOGBOOK: drawer in block content.
+func ParseClockEntries(markdown string, location *time.Location) []ClockEntry {
+	entries := []ClockEntry{}
+	inLogbook := false
+
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == logbookStart:
+			inLogbook = true
+		case line == drawerEnd:
+			inLogbook = false
+		case inLogbook:
+			if entry, ok := parseClockLine(line, location); ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries
+}
+
+func parseClockLine(line string, location *time.Location) (ClockEntry, bool) {
+	match := clockRe.FindStringSubmatch(line)
+	if match == nil {
+		return ClockEntry{}, false
+	}
+
+	start, ok := parseClockTime(match[1], location)
+	if !ok {
+		return ClockEntry{}, false
+	}
+
+	entry := ClockEntry{Start: start}
+
+	if match[2] != "" {
+		end, ok := parseClockTime(match[2], location)
+		if !ok {
+			return ClockEntry{}, false
+		}
+
+		entry.End = &end
+	}
+
+	return entry, true
+}
+
+func parseClockTime(text string, location *time.Location) (time.Time, bool) {
+	match := clockTimeRe.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	layout := "2006-01-02 15:04"
+	if strings.Count(match[2], ":") == 2 {
+		layout = "2006-01-02 15:04:05"
+	}
+
+	parsed, err := time.ParseInLocation(layout, match[1]+" "+match[2], location)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return parsed, true
+}
+
+// TaskContent returns task block content without its marker, priority, planning lines
+// or logbook, leaving just what the task is about.
+func TaskContent(markdown string) string {
+	if match := taskMarkerRe.FindString(markdown); match != "" {
+		markdown = markdown[len(match):]
+		markdown = taskPriorityRe.ReplaceAllString(markdown, "")
+	}
+
+	lines := []string{}
+
+	for _, line := range strings.Split(StripLogbook(markdown), "\n") {
+		if taskPlanningRe.MatchString(line) {
+			line = strings.TrimSpace(taskPlanningRe.ReplaceAllString(line, ""))
+			if line == "" {
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// StripLogbook removes the :LOGBOOK: drawer from block content.
+func StripLogbook(markdown string) string {
+	if !strings.Contains(markdown, logbookStart) {
+		return markdown
+	}
+
+	lines := []string{}
+	inLogbook := false
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == logbookStart:
+			inLogbook = true
+		case inLogbook:
+			inLogbook = trimmed != drawerEnd
+		default:
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,168 @@
+package graph_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+)
+
+const taskMarkdown = `DONE [#A] Write the report [[Project X]]
+SCHEDULED: <2024-06-03 Mon 09:30 .+1w>
+DEADLINE: <2024-06-07 Fri>
+:LOGBOOK:
+CLOCK: [2024-06-03 Mon 10:00:00]--[2024-06-03 Mon 11:30:00] =>  01:30:00
+CLOCK: [2024-06-04 Tue 14:00]--[2024-06-04 Tue 14:15] =>  00:15:00
+CLOCK: [2024-06-05 Wed 09:00:00]
+:END:
+More notes`
+
+func TestTask_ParseTask(t *testing.T) {
+	task := graph.ParseTask(taskMarkdown, time.UTC)
+
+	require.NotNil(t, task)
+	assert.Equal(t, graph.TaskMarkerDone, task.Marker)
+	assert.Equal(t, "A", task.Priority)
+	assert.True(t, task.IsDone())
+	assert.True(t, task.IsClosed())
+
+	require.NotNil(t, task.Scheduled)
+	assert.Equal(t, time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC), task.Scheduled.Time)
+	assert.True(t, task.Scheduled.HasTime)
+	assert.Equal(t, ".+1w", task.Scheduled.Repeater)
+
+	require.NotNil(t, task.Deadline)
+	assert.Equal(t, time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC), task.Deadline.Time)
+	assert.False(t, task.Deadline.HasTime)
+
+	require.Len(t, task.Clock, 3)
+	assert.Equal(t, 90*time.Minute, task.Clock[0].Duration())
+	assert.Equal(t, 15*time.Minute, task.Clock[1].Duration())
+	assert.Nil(t, task.Clock[2].End)
+	assert.Equal(t, 105*time.Minute, task.ClockedTime())
+}
+
+func TestTask_ParseTask_Markers(t *testing.T) {
+	markerTests := []struct {
+		markdown string
+		want     graph.TaskMarker
+	}{
+		{"TODO task", graph.TaskMarkerTodo},
+		{"DOING task", graph.TaskMarkerDoing},
+		{"IN-PROGRESS task", graph.TaskMarkerDoing},
+		{"CANCELLED task", graph.TaskMarkerCanceled},
+		{"WAIT task", graph.TaskMarkerWaiting},
+		{"LATER", graph.TaskMarkerLater},
+	}
+
+	for _, tt := range markerTests {
+		task := graph.ParseTask(tt.markdown, time.UTC)
+
+		require.NotNil(t, task, tt.markdown)
+		assert.Equal(t, tt.want, task.Marker, tt.markdown)
+	}
+
+	assert.Nil(t, graph.ParseTask("TODOS are not tasks", time.UTC))
+	assert.Nil(t, graph.ParseTask("plain block", time.UTC))
+}
+
+func TestTask_TaskContent(t *testing.T) {
+	assert.Equal(t, "Write the report [[Project X]]\nMore notes", graph.TaskContent(taskMarkdown))
+	assert.Equal(t, "plain block", graph.TaskContent("plain block"))
+}
+
+func TestBlock_Task(t *testing.T) {
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlock(&page, []string{"LATER [#B] call back"}, 1)
+
+	require.NoError(t, err)
+	assert.True(t, block.IsTask())
+	assert.Equal(t, "B", block.Task.Priority)
+}
+
+func TestBlock_Task_StripsLogbook(t *testing.T) {
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlock(&page, []string{
+		"DONE tidy up",
+		":LOGBOOK:",
+		"CLOCK: [2024-06-03 Mon 10:00:00]--[2024-06-03 Mon 11:30:00] =>  01:30:00",
+		":END:",
+	}, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "DONE tidy up", block.Content.Markdown)
+	require.Len(t, block.Task.Clock, 1)
+	assert.Equal(t, 90*time.Minute, block.Task.ClockedTime())
+}
+
+func TestBlock_SetContent(t *testing.T) {
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlock(&page, []string{
+		"TODO tidy up",
+		":LOGBOOK:",
+		"CLOCK: [2024-06-03 Mon 10:00:00]--[2024-06-03 Mon 11:30:00] =>  01:30:00",
+		":END:",
+	}, 1)
+
+	require.NoError(t, err)
+	require.NoError(t, block.SetContent("DONE [#A] tidy up"))
+	require.NotNil(t, block.Task)
+	assert.Equal(t, graph.TaskMarkerDone, block.Task.Marker)
+	assert.Equal(t, "A", block.Task.Priority)
+	assert.Equal(t, 90*time.Minute, block.Task.ClockedTime())
+
+	require.NoError(t, block.SetContent("tidy up"))
+	assert.False(t, block.IsTask())
+
+	empty := graph.NewEmptyBlock()
+
+	require.NoError(t, empty.SetContent("NOW write\nSCHEDULED: <2024-06-03 Mon>"))
+	require.NotNil(t, empty.Task)
+	assert.Equal(t, graph.TaskMarkerNow, empty.Task.Marker)
+	assert.NotNil(t, empty.Task.Scheduled)
+}
+
+func TestTask_ParseTask_Location(t *testing.T) {
+	location := time.FixedZone("UTC+9", 9*60*60)
+	task := graph.ParseTask(taskMarkdown, location)
+
+	require.NotNil(t, task)
+	assert.Equal(t, time.Date(2024, 6, 3, 9, 30, 0, 0, location), task.Scheduled.Time)
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 30, 0, 0, time.UTC), task.Scheduled.Time.UTC())
+	assert.Equal(t, time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC), task.Clock[0].Start.UTC())
+}
+
+func TestBlock_NewBlockInLocation(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlockInLocation(&page, []string{"Call", "SCHEDULED: <2024-07-01 Mon 14:00>"}, 1, location)
+
+	require.NoError(t, err)
+
+	scheduled, _ := block.Planning()
+
+	require.NotNil(t, scheduled)
+	assert.Equal(t, time.Date(2024, 7, 1, 14, 0, 0, 0, location), scheduled.Time)
+
+	require.NoError(t, block.SetContent("TODO Call\nSCHEDULED: <2024-07-02 Tue 09:00>"))
+	require.NotNil(t, block.Task)
+	assert.Equal(t, time.Date(2024, 7, 2, 9, 0, 0, 0, location), block.Task.Scheduled.Time)
+}
+
+func TestBlock_Planning(t *testing.T) {
+	page := graph.NewEmptyPage()
+	block, err := graph.NewBlock(&page, []string{"Dentist appointment", "SCHEDULED: <2024-07-01 Mon 14:00 +6m>"}, 1)
+
+	require.NoError(t, err)
+	assert.False(t, block.IsTask())
+
+	scheduled, deadline := block.Planning()
+
+	require.NotNil(t, scheduled)
+	assert.Equal(t, time.Date(2024, 7, 1, 14, 0, 0, 0, time.Local), scheduled.Time)
+	assert.Equal(t, "+6m", scheduled.Repeater)
+	assert.Nil(t, deadline)
+}
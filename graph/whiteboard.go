@@ -0,0 +1,83 @@
+package graph
+
+// Whiteboard shape types that get special treatment.
+const (
+	ShapeTypeText      = "text"
+	ShapeTypeBox       = "box"
+	ShapeTypeEllipse   = "ellipse"
+	ShapeTypeLine      = "line"
+	ShapeTypePencil    = "pencil"
+	ShapeTypeHighlight = "highlighter"
+	ShapeTypePortal    = "logseq-portal"
+)
+
+// Point is a position on a whiteboard canvas.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// WhiteboardShape is a single tldraw shape on a whiteboard.
+// Points are relative to the shape's position.
+type WhiteboardShape struct {
+	ID      string  `json:"id"`
+	BlockID string  `json:"block_id"`
+	Type    string  `json:"type"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+	Text    string  `json:"text,omitempty"`
+	Stroke  string  `json:"stroke,omitempty"`
+	Fill    string  `json:"fill,omitempty"`
+	Points  []Point `json:"points,omitempty"`
+	// Portals embed a page (by name) or a block (by ID).
+	PortalTarget string `json:"portal_target,omitempty"`
+	PortalIsPage bool   `json:"portal_is_page,omitempty"`
+}
+
+// IsPortal returns true if the shape embeds a page or block.
+func (s *WhiteboardShape) IsPortal() bool {
+	return s.Type == ShapeTypePortal && s.PortalTarget != ""
+}
+
+// Whiteboard holds the shapes drawn on a whiteboard page.
+type Whiteboard struct {
+	Shapes []WhiteboardShape `json:"shapes"`
+}
+
+// NewWhiteboard creates an empty Whiteboard.
+func NewWhiteboard() *Whiteboard {
+	return &Whiteboard{
+		Shapes: []WhiteboardShape{},
+	}
+}
+
+// Bounds returns the top left and bottom right corners enclosing every shape.
+func (w *Whiteboard) Bounds() (Point, Point) {
+	if len(w.Shapes) == 0 {
+		return Point{}, Point{}
+	}
+
+	first := w.Shapes[0]
+	topLeft := Point{first.X, first.Y}
+	bottomRight := Point{first.X, first.Y}
+
+	include := func(x, y float64) {
+		topLeft.X = min(topLeft.X, x)
+		topLeft.Y = min(topLeft.Y, y)
+		bottomRight.X = max(bottomRight.X, x)
+		bottomRight.Y = max(bottomRight.Y, y)
+	}
+
+	for _, shape := range w.Shapes {
+		include(shape.X, shape.Y)
+		include(shape.X+shape.Width, shape.Y+shape.Height)
+
+		for _, point := range shape.Points {
+			include(shape.X+point.X, shape.Y+point.Y)
+		}
+	}
+
+	return topLeft, bottomRight
+}
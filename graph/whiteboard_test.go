@@ -0,0 +1,45 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+)
+
+func TestWhiteboard_Bounds_Empty(t *testing.T) {
+	whiteboard := graph.NewWhiteboard()
+	topLeft, bottomRight := whiteboard.Bounds()
+
+	assert.Equal(t, graph.Point{}, topLeft)
+	assert.Equal(t, graph.Point{}, bottomRight)
+}
+
+func TestWhiteboard_Bounds(t *testing.T) {
+	whiteboard := graph.NewWhiteboard()
+	whiteboard.Shapes = []graph.WhiteboardShape{
+		{Type: graph.ShapeTypeBox, X: 10, Y: 20, Width: 100, Height: 50},
+		{Type: graph.ShapeTypeLine, X: -5, Y: 0, Points: []graph.Point{{X: 0, Y: 0}, {X: 300, Y: 200}}},
+	}
+
+	topLeft, bottomRight := whiteboard.Bounds()
+
+	assert.Equal(t, graph.Point{X: -5, Y: 0}, topLeft)
+	assert.Equal(t, graph.Point{X: 295, Y: 200}, bottomRight)
+}
+
+func TestWhiteboardShape_IsPortal(t *testing.T) {
+	isPortalTests := []struct {
+		shape graph.WhiteboardShape
+		want  bool
+	}{
+		{graph.WhiteboardShape{Type: graph.ShapeTypePortal, PortalTarget: "Some Page"}, true},
+		{graph.WhiteboardShape{Type: graph.ShapeTypePortal}, false},
+		{graph.WhiteboardShape{Type: graph.ShapeTypeBox, PortalTarget: "Some Page"}, false},
+	}
+
+	for _, tt := range isPortalTests {
+		assert.Equal(t, tt.want, tt.shape.IsPortal())
+	}
+}
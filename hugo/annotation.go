@@ -0,0 +1,75 @@
+package hugo
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"export-logseq/graph"
+)
+
+func isPDF(assetPath string) bool {
+	return strings.EqualFold(filepath.Ext(assetPath), ".pdf")
+}
+
+// annotatedPDFLink returns the permalink of the PDF an annotation page highlights,
+// if the PDF is published.
+func (e *Exporter) annotatedPDFLink(page graph.Page) string {
+	asset, ok := e.Graph.AnnotatedAsset(&page)
+	if !ok {
+		return ""
+	}
+
+	permalink, _ := e.PermalinkForAsset(asset.Path)
+
+	return permalink
+}
+
+// renderAnnotation turns a PDF highlight into a quote in a logseq/annotation shortcode,
+// linking to its page in the PDF when PDFs are published. Area highlights quote their image.
+func (e *Exporter) renderAnnotation(block graph.Block, annotation graph.Annotation, content string) string {
+	link := ""
+	pdfPath := ""
+
+	if page, err := e.Graph.FindPage(block.PageName); err == nil {
+		pdfPath, _ = page.AnnotatedAssetPath()
+
+		if permalink := e.annotatedPDFLink(*page); permalink != "" {
+			link = fmt.Sprintf("%s#page=%d", permalink, annotation.Page)
+		}
+	}
+
+	if annotation.Area {
+		imagePath := graph.AnnotationImagePath(pdfPath, &block, annotation)
+
+		imageLink, ok := e.PermalinkForAsset(imagePath)
+		if ok {
+			content = fmt.Sprintf("![Page %d highlight](%s)", annotation.Page, imageLink)
+		} else {
+			log.Warnf("No image found for area highlight %s: %s", block.ID, imagePath)
+
+			content = UnavailableLink(fmt.Sprintf("Page %d highlight", annotation.Page))
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+
+	return fmt.Sprintf(`{{%% logseq/annotation page="%d" color="%s" link="%s" %%}}`, annotation.Page, annotation.Color, link) +
+		"\n" + strings.Join(lines, "\n") + "\n{{% /logseq/annotation %}}"
+}
+
+// annotationReference is the text of a block reference to a PDF highlight: its page number
+// and what it quotes.
+func annotationReference(block graph.Block, annotation graph.Annotation) string {
+	text, _ := blockText(block)
+	if annotation.Area {
+		text = "area highlight"
+	}
+
+	return fmt.Sprintf("p. %d: %s", annotation.Page, text)
+}
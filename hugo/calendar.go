@@ -0,0 +1,315 @@
+package hugo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"export-logseq/graph"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	calendarFileName = "logseq.ics"
+	calendarProdID   = "-//export-logseq//Logseq Calendar//EN"
+	calendarUIDHost  = "export-logseq"
+
+	// RFC 5545 content lines should be folded at 75 octets.
+	calendarLineLimit = 75
+)
+
+var (
+	calendarRepeaterRe = regexp.MustCompile(`^(?:\.\+|\+\+|\+)(\d+)([hdwmy])$`)
+)
+
+var calendarFrequencies = map[string]string{
+	"h": "HOURLY",
+	"d": "DAILY",
+	"w": "WEEKLY",
+	"m": "MONTHLY",
+	"y": "YEARLY",
+}
+
+var calendarTaskStatuses = map[graph.TaskMarker]string{
+	graph.TaskMarkerDoing:    "IN-PROCESS",
+	graph.TaskMarkerNow:      "IN-PROCESS",
+	graph.TaskMarkerDone:     "COMPLETED",
+	graph.TaskMarkerCanceled: "CANCELLED",
+}
+
+var calendarTaskPriorities = map[string]string{
+	"A": "1",
+	"B": "5",
+	"C": "9",
+}
+
+// ExportCalendar writes scheduled and deadline blocks to an iCalendar file under static/,
+// so it can be subscribed to from the published site.
+func (e *Exporter) ExportCalendar() (int, error) {
+	exportDir := filepath.Join(e.SiteDir, "static")
+
+	if err := os.MkdirAll(exportDir, folderPermissions); err != nil {
+		return 0, errors.Wrap(err, "creating calendar directory "+exportDir)
+	}
+
+	calendar, count := e.RenderCalendar(e.ExportTime)
+	calendarPath := filepath.Join(exportDir, calendarFileName)
+
+	file, err := os.Create(calendarPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating calendar file")
+	}
+
+	defer file.Close()
+
+	if _, err := file.WriteString(calendar); err != nil {
+		return 0, errors.Wrap(err, "writing calendar file")
+	}
+
+	return count, nil
+}
+
+// RenderCalendar builds an iCalendar document with a VTODO for each scheduled or deadline task,
+// and a VEVENT for other blocks with a SCHEDULED or DEADLINE date. It also returns how many
+// components the calendar holds.
+func (e *Exporter) RenderCalendar(stamp time.Time) (string, int) {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendarProdID,
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Logseq",
+	}
+	count := 0
+
+	// Times are floating, like Logseq's. Calendar apps that know this hint show them in the graph's zone.
+	if e.Location != nil && e.Location.String() != "Local" {
+		lines = append(lines, "X-WR-TIMEZONE:"+e.Location.String())
+	}
+
+	for _, page := range e.calendarPages() {
+		for _, block := range page.AllBlocks {
+			if e.skipInCalendar(block) {
+				continue
+			}
+
+			component := e.calendarComponent(*block, stamp)
+			if component == nil {
+				continue
+			}
+
+			lines = append(lines, component...)
+			count++
+		}
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	var sb strings.Builder
+
+	for _, line := range lines {
+		sb.WriteString(foldCalendarLine(line))
+		sb.WriteString("\r\n")
+	}
+
+	return sb.String(), count
+}
+
+// calendarPages lists graph pages in name order, so calendar output is stable between exports.
+func (e *Exporter) calendarPages() []*graph.Page {
+	pages := []*graph.Page{}
+
+	for _, page := range e.Graph.Pages {
+		pages = append(pages, page)
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Name < pages[j].Name
+	})
+
+	return pages
+}
+
+// skipInCalendar returns true if the export leaves out a block or any block above it,
+// because it's private or a task the task policy hides.
+func (e *Exporter) skipInCalendar(block *graph.Block) bool {
+	for current := block; current != nil; current = current.Parent {
+		if e.ShouldSkipBlock(*current) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// calendarComponent returns the content lines for a block's calendar entry,
+// or nil if the block has no SCHEDULED or DEADLINE date.
+func (e *Exporter) calendarComponent(block graph.Block, stamp time.Time) []string {
+	scheduled, deadline := block.Planning()
+	if scheduled == nil && deadline == nil {
+		return nil
+	}
+
+	componentType := "VEVENT"
+	if block.IsTask() {
+		componentType = "VTODO"
+	}
+
+	summary, description := blockText(block)
+	lines := []string{
+		"BEGIN:" + componentType,
+		"UID:" + block.ID + "@" + calendarUIDHost,
+		"DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"),
+		"SUMMARY:" + escapeCalendarText(summary),
+	}
+
+	if description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeCalendarText(description))
+	}
+
+	rrule := calendarRepeatRule(scheduled, deadline)
+
+	if block.IsTask() {
+		start, due := calendarTaskDates(scheduled, deadline, rrule != "")
+
+		if start != nil {
+			lines = append(lines, calendarDateLine("DTSTART", *start))
+		}
+
+		if due != nil {
+			lines = append(lines, calendarDateLine("DUE", *due))
+		}
+
+		lines = append(lines, "STATUS:"+calendarTaskStatus(*block.Task))
+
+		if priority, ok := calendarTaskPriorities[block.Task.Priority]; ok {
+			lines = append(lines, "PRIORITY:"+priority)
+		}
+	} else {
+		start := scheduled
+		if start == nil {
+			start = deadline
+		}
+
+		lines = append(lines, calendarDateLine("DTSTART", *start))
+	}
+
+	if rrule != "" {
+		lines = append(lines, "RRULE:"+rrule)
+	}
+
+	// URL must be absolute, so it needs the site URL.
+	if permalink := e.PermalinkForBlock(block); permalink != "" && e.SiteURL != "" {
+		lines = append(lines, "URL:"+e.SiteURL+permalink)
+	}
+
+	lines = append(lines, "END:"+componentType)
+
+	return lines
+}
+
+// calendarTaskDates picks a VTODO's start and due dates. iCalendar needs both to be
+// dates or both to be date-times, and repeating tasks need a start.
+func calendarTaskDates(
+	scheduled *graph.TaskTimestamp, deadline *graph.TaskTimestamp, repeats bool,
+) (*graph.TaskTimestamp, *graph.TaskTimestamp) {
+	if scheduled == nil && repeats {
+		scheduled = deadline
+	}
+
+	if scheduled != nil && deadline != nil && scheduled.HasTime != deadline.HasTime {
+		start, due := *scheduled, *deadline
+		start.HasTime, due.HasTime = false, false
+
+		return &start, &due
+	}
+
+	return scheduled, deadline
+}
+
+func calendarTaskStatus(task graph.Task) string {
+	if status, ok := calendarTaskStatuses[task.Marker]; ok {
+		return status
+	}
+
+	return "NEEDS-ACTION"
+}
+
+// calendarDateLine formats a timestamp as an all-day date, or as a floating date-time if it has
+// a time of day. Logseq times are wall-clock times, so a 09:30 task stays at 09:30 wherever
+// the calendar is read.
+func calendarDateLine(name string, timestamp graph.TaskTimestamp) string {
+	if !timestamp.HasTime {
+		return name + ";VALUE=DATE:" + timestamp.Time.Format("20060102")
+	}
+
+	return name + ":" + timestamp.Time.Format("20060102T150405")
+}
+
+// calendarRepeatRule maps a Logseq repeater like "+1w" to an RRULE.
+// Repeaters that count from completion (".+") or skip past dates ("++") can't be
+// expressed in iCalendar, so they repeat from the original date instead.
+func calendarRepeatRule(scheduled *graph.TaskTimestamp, deadline *graph.TaskTimestamp) string {
+	repeater := ""
+
+	if scheduled != nil && scheduled.Repeater != "" {
+		repeater = scheduled.Repeater
+	} else if deadline != nil {
+		repeater = deadline.Repeater
+	}
+
+	match := calendarRepeaterRe.FindStringSubmatch(repeater)
+	if match == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("FREQ=%s;INTERVAL=%s", calendarFrequencies[match[2]], match[1])
+}
+
+// escapeCalendarText escapes characters that have meaning in iCalendar TEXT values.
+func escapeCalendarText(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+
+	return replacer.Replace(text)
+}
+
+// foldCalendarLine splits long content lines, continuing them on lines that start with a space.
+// Lines are only split between UTF-8 characters.
+func foldCalendarLine(line string) string {
+	if len(line) <= calendarLineLimit {
+		return line
+	}
+
+	var sb strings.Builder
+
+	lineLength := 0
+	limit := calendarLineLimit
+
+	for _, r := range line {
+		runeLength := len(string(r))
+
+		if lineLength+runeLength > limit {
+			sb.WriteString("\r\n ")
+
+			lineLength = 0
+			limit = calendarLineLimit - 1
+		}
+
+		sb.WriteRune(r)
+
+		lineLength += runeLength
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package hugo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/hugo"
+)
+
+func TestExporter_RenderCalendar_FloatingTimes(t *testing.T) {
+	berlin := time.FixedZone("Europe/Berlin", 2*60*60)
+	g := graph.NewGraph()
+	page := graph.NewEmptyPage()
+	page.Name, page.Title = "Plans", "Plans"
+	page.Root.PageName = page.Name
+
+	timed, err := graph.NewBlockInLocation(&page, []string{"Dentist", "SCHEDULED: <2024-06-03 Mon 09:30>"}, 1, berlin)
+	require.NoError(t, err)
+	allDay, err := graph.NewBlockInLocation(&page, []string{"Holiday", "SCHEDULED: <2024-06-04 Tue>"}, 1, berlin)
+	require.NoError(t, err)
+
+	page.Root.AddChild(timed)
+	page.Root.AddChild(allDay)
+	page.SetRoot(page.Root)
+	require.NoError(t, g.AddPage(&page))
+
+	exporter := hugo.Exporter{Graph: g, Location: berlin}
+	calendar, count := exporter.RenderCalendar(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, 2, count)
+	assert.Contains(t, calendar, "X-WR-TIMEZONE:Europe/Berlin\r\n")
+	assert.Contains(t, calendar, "DTSTART:20240603T093000\r\n")
+	assert.Contains(t, calendar, "DTSTART;VALUE=DATE:20240604\r\n")
+	assert.NotContains(t, calendar, "T073000Z")
+}
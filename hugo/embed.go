@@ -0,0 +1,141 @@
+package hugo
+
+import (
+	"fmt"
+	"strings"
+
+	"export-logseq/graph"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Embeds nested deeper than this are exported as plain links.
+const maxEmbedDepth = 5
+
+// ProcessEmbed inlines the page or block named by an embed link, wrapped in a logseq/embed shortcode.
+// Embeds that would loop back on themselves, or nest too deeply, become plain links instead.
+// embedTrail starts with the page being exported, followed by the embeds around this one.
+func (e *Exporter) ProcessEmbed(block graph.Block, link graph.Link, embedTrail []string) (string, error) {
+	embedPath := link.LinkPath
+
+	// Page embeds may use an alias, so key them by the page they find.
+	if link.LinkType == graph.LinkTypePage {
+		if page, err := e.Graph.FindPage(link.LinkPath); err == nil {
+			embedPath = page.Name
+		}
+	}
+
+	embedKey := embedTrailKey(link.LinkType, embedPath)
+
+	if embedDepth(embedTrail) >= maxEmbedDepth {
+		log.Warnf("Embed nested too deeply in block %s: %s", block.ID, link.LinkPath)
+
+		return e.ProcessBlockLink(link), nil
+	}
+
+	if embedCycles(block, link.LinkType, embedPath, embedKey, embedTrail) {
+		log.Warnf("Embed cycle in block %s: %s", block.ID, link.LinkPath)
+
+		return e.ProcessBlockLink(link), nil
+	}
+
+	// Copy the trail so sibling embeds don't see each other.
+	embedTrail = append(append([]string{}, embedTrail...), embedKey)
+
+	switch link.LinkType {
+	case graph.LinkTypePage:
+		return e.processPageEmbed(link, embedTrail)
+	case graph.LinkTypeBlock:
+		return e.processBlockEmbed(link, embedTrail)
+	default:
+		return e.ProcessBlockLink(link), nil
+	}
+}
+
+func (e *Exporter) processPageEmbed(link graph.Link, embedTrail []string) (string, error) {
+	page, err := e.Graph.FindPage(link.LinkPath)
+	if err != nil || page.IsPlaceholder() {
+		log.Warn("Page not found for embed: ", link.LinkPath)
+
+		return UnavailableLink(link.Label), nil
+	}
+
+	permalink, ok := e.PermalinkForPage(page.Name)
+	if !ok {
+		return UnavailableLink(link.Label), nil
+	}
+
+	content, err := e.processBlock(*page.Root, embedTrail)
+	if err != nil {
+		return "", errors.Wrap(err, "processing embedded page "+page.Name)
+	}
+
+	return embedShortcode("page", permalink, page.Title, content), nil
+}
+
+func (e *Exporter) processBlockEmbed(link graph.Link, embedTrail []string) (string, error) {
+	targetBlock, ok := e.Graph.Blocks[link.LinkPath]
+	if !ok {
+		log.Warn("Block not found for embed: ", link.LinkPath)
+
+		return UnavailableLink(link.Label), nil
+	}
+
+	permalink := e.PermalinkForBlock(*targetBlock)
+
+	content, err := e.processBlock(*targetBlock, embedTrail)
+	if err != nil {
+		return "", errors.Wrap(err, "processing embedded block "+targetBlock.ID)
+	}
+
+	return embedShortcode("block", permalink, targetBlock.PageName, content), nil
+}
+
+// embedDepth returns how many embeds an embed trail holds, not counting the exported page.
+func embedDepth(embedTrail []string) int {
+	if len(embedTrail) == 0 {
+		return 0
+	}
+
+	return len(embedTrail) - 1
+}
+
+// embedTrailKey identifies a page or block in an embed trail.
+func embedTrailKey(linkType graph.LinkType, path string) string {
+	return string(linkType) + ":" + strings.ToLower(path)
+}
+
+// embedCycles returns true if an embed would include the block that contains it.
+func embedCycles(
+	block graph.Block, linkType graph.LinkType, embedPath string, embedKey string, embedTrail []string,
+) bool {
+	for _, key := range embedTrail {
+		if key == embedKey {
+			return true
+		}
+	}
+
+	if linkType == graph.LinkTypePage {
+		return strings.EqualFold(embedPath, block.PageName)
+	}
+
+	if embedPath == block.ID {
+		return true
+	}
+
+	for parent := block.Parent; parent != nil; parent = parent.Parent {
+		if embedPath == parent.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func embedShortcode(embedType string, permalink string, title string, content string) string {
+	return fmt.Sprintf(
+		`{{%% logseq/embed type="%s" link="%s" title="%s" %%}}%s{{%% /logseq/embed %%}}`,
+		embedType, shortcodeEscape(permalink), shortcodeEscape(title), content,
+	)
+}
@@ -0,0 +1,75 @@
+package hugo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/graph/graphtest"
+	"export-logseq/hugo"
+)
+
+func newExporter(g graph.Graph) *hugo.Exporter {
+	e := &hugo.Exporter{Graph: g}
+	e.PagePermalinks = e.SetPagePermalinks()
+
+	return e
+}
+
+func TestExporter_ProcessEmbed_SelfEmbed(t *testing.T) {
+	g := graph.NewGraph()
+	page := graphtest.AddPage(t, &g, "Loop", nil, "{{embed [[Loop]]}}")
+
+	content, err := newExporter(g).ProcessBlock(*page.Root)
+
+	require.NoError(t, err)
+	assert.NotContains(t, content, "logseq/embed")
+	assert.Contains(t, content, `{{< page-link link="/pages/loop" >}}Loop{{< /page-link >}}`)
+}
+
+func TestExporter_ProcessEmbed_Cycle(t *testing.T) {
+	g := graph.NewGraph()
+	first := graphtest.AddPage(t, &g, "First", nil, "{{embed [[Second]]}}")
+	graphtest.AddPage(t, &g, "Second", nil, "{{embed [[First]]}}")
+
+	content, err := newExporter(g).ProcessBlock(*first.Root)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(content, "{{% logseq/embed "))
+	assert.Contains(t, content, `{{< page-link link="/pages/first" >}}First{{< /page-link >}}`)
+}
+
+func TestExporter_ProcessEmbed_DepthLimit(t *testing.T) {
+	g := graph.NewGraph()
+	pageCount := 7
+
+	for i := 0; i < pageCount; i++ {
+		graphtest.AddPage(t, &g, fmt.Sprintf("Level %d", i), nil, fmt.Sprintf("{{embed [[Level %d]]}}", i+1))
+	}
+
+	top, err := g.FindPage("Level 0")
+	require.NoError(t, err)
+
+	content, err := newExporter(g).ProcessBlock(*top.Root)
+
+	require.NoError(t, err)
+	// Five nested embeds are inlined, and the sixth is a link.
+	assert.Equal(t, 5, strings.Count(content, "{{% logseq/embed "))
+	assert.Contains(t, content, `title="Level 5"`)
+	assert.Contains(t, content, `{{< page-link link="/pages/level-6" >}}Level 6{{< /page-link >}}`)
+}
+
+func TestExporter_ProcessEmbed_EscapesArgs(t *testing.T) {
+	g := graph.NewGraph()
+	top := graphtest.AddPage(t, &g, "Top", nil, `{{embed [[Say "hi"]]}}`)
+	graphtest.AddPage(t, &g, `Say "hi"`, nil, "hello")
+
+	content, err := newExporter(g).ProcessBlock(*top.Root)
+
+	require.NoError(t, err)
+	assert.Contains(t, content, `title="Say \"hi\""`)
+}
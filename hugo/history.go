@@ -0,0 +1,122 @@
+package hugo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"export-logseq/graph"
+)
+
+// PermalinkForHistory determines the permalink for a page's history view.
+func (e *Exporter) PermalinkForHistory(page graph.Page) (string, bool) {
+	permalink, ok := e.PermalinkForPage(page.Name)
+	if !ok {
+		return "", false
+	}
+
+	if permalink == "/" {
+		return "/history", true
+	}
+
+	return permalink + "-history", true
+}
+
+// exportPageHistory writes a page's history view next to the page, if it has any history.
+func (e *Exporter) exportPageHistory(page graph.Page) error {
+	if len(page.History) == 0 {
+		return nil
+	}
+
+	permalink, ok := e.PermalinkForHistory(page)
+	if !ok {
+		return errors.New("no permalink found for page history: " + page.Name)
+	}
+
+	contentPath := filepath.Join(e.ContentDir, filepath.FromSlash(permalink)) + ".md"
+	if err := os.MkdirAll(filepath.Dir(contentPath), folderPermissions); err != nil {
+		return errors.Wrap(err, "creating history content folder")
+	}
+
+	pagePermalink, _ := e.PermalinkForPage(page.Name)
+	frontmatter := struct {
+		Title     string `json:"title"`
+		HistoryOf string `json:"history_of"`
+	}{
+		Title:     "History of " + page.Title,
+		HistoryOf: pagePermalink,
+	}
+
+	frontmatterBytes, err := json.Marshal(frontmatter)
+	if err != nil {
+		return errors.Wrap(err, "encoding history frontmatter")
+	}
+
+	fileContent := fmt.Sprintf("---\n%s\n---\n%s", frontmatterBytes, e.ProcessHistory(page.History))
+
+	file, err := os.Create(contentPath)
+	if err != nil {
+		return errors.Wrap(err, "creating history content file")
+	}
+
+	defer file.Close()
+
+	if _, err := file.WriteString(fileContent); err != nil {
+		return errors.Wrap(err, "writing history content to file")
+	}
+
+	return nil
+}
+
+// ProcessHistory describes page versions as Markdown, newest first, with each version's
+// block changes shown as a diff.
+func (e *Exporter) ProcessHistory(history []graph.PageVersion) string {
+	var sb strings.Builder
+
+	for i := len(history) - 1; i >= 0; i-- {
+		version := history[i]
+		heading := version.Source
+
+		if !version.Timestamp.IsZero() {
+			heading = version.Timestamp.UTC().Format(time.RFC3339) + " (" + version.Source + ")"
+		}
+
+		diff := ""
+
+		for _, change := range version.Changes {
+			if change.Before != "" {
+				diff += diffLines("-", change.Before)
+			}
+
+			if change.After != "" {
+				diff += diffLines("+", change.After)
+			}
+		}
+
+		// Make the fence longer than any code fence in the block content.
+		fence := "```"
+		for strings.Contains(diff, fence) {
+			fence += "`"
+		}
+
+		fmt.Fprintf(&sb, "\n## %s\n\n%sdiff\n%s%s\n", heading, fence, diff, fence)
+	}
+
+	return sb.String()
+}
+
+// diffLines prefixes each line of block content with a diff marker.
+func diffLines(marker string, content string) string {
+	lines := strings.Split(content, "\n")
+
+	for i, line := range lines {
+		lines[i] = marker + " " + line
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,53 @@
+package hugo
+
+import (
+	"fmt"
+	"strings"
+
+	"export-logseq/graph"
+)
+
+// mathPlaceholder stands in for math while links and shortcodes are processed,
+// so nothing inside it is replaced.
+func mathPlaceholder(index int) string {
+	return fmt.Sprintf("\x00math-%d\x00", index)
+}
+
+// ProcessMarkup turns highlights into <mark> elements and sets math aside in a placeholder.
+// It returns the content and the logseq/math shortcodes to put back with InsertMath.
+func ProcessMarkup(content string) (string, []string) {
+	markups := graph.FindMarkup(content)
+	math := []string{}
+	replacements := make([]string, len(markups))
+
+	for i, markup := range markups {
+		switch markup.Kind {
+		case graph.MarkupMath, graph.MarkupDisplayMath:
+			display := markup.Kind == graph.MarkupDisplayMath
+			raw := content[markup.Span.Start:markup.Span.End]
+			math = append(math, fmt.Sprintf(`{{< logseq/math display="%t" >}}%s{{< /logseq/math >}}`, display, raw))
+			replacements[i] = mathPlaceholder(len(math) - 1)
+		case graph.MarkupHighlightOpen:
+			replacements[i] = graph.HighlightOpenTag(markup.Color)
+		case graph.MarkupHighlightClose:
+			replacements[i] = "</mark>"
+		}
+	}
+
+	// Replace from the end, so earlier spans stay put.
+	for i := len(markups) - 1; i >= 0; i-- {
+		span := markups[i].Span
+		content = content[:span.Start] + replacements[i] + content[span.End:]
+	}
+
+	return content, math
+}
+
+// InsertMath replaces math placeholders with their shortcodes.
+func InsertMath(content string, math []string) string {
+	for i, shortcode := range math {
+		content = strings.Replace(content, mathPlaceholder(i), shortcode, 1)
+	}
+
+	return content
+}
@@ -0,0 +1,100 @@
+package hugo
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"export-logseq/graph"
+)
+
+var backtickRunRe = regexp.MustCompile("`{3,}")
+
+// renderContentNodes turns block content split around Org blocks into Hugo content.
+func (e *Exporter) renderContentNodes(block graph.Block, nodes []graph.ContentNode, embedTrail []string) (string, error) {
+	parts := []string{}
+
+	for _, node := range nodes {
+		if node.Block == nil {
+			text, err := e.processText(block, node.Text, embedTrail)
+			if err != nil {
+				return "", err
+			}
+
+			parts = append(parts, text)
+
+			continue
+		}
+
+		rendered, err := e.renderOrgBlock(block, *node.Block, embedTrail)
+		if err != nil {
+			return "", errors.Wrapf(err, "rendering %s block", node.Block.Kind)
+		}
+
+		if rendered != "" {
+			parts = append(parts, rendered)
+		}
+	}
+
+	return strings.Join(parts, "\n"), nil
+}
+
+// renderOrgBlock turns a #+BEGIN_KIND ... #+END_KIND block into Hugo content.
+// Comments are dropped, SRC and EXAMPLE become fenced code, QUOTE becomes a blockquote,
+// EXPORT html passes through, and QUERY runs the query. Other kinds, like NOTE and TIP,
+// go in a logseq/callout shortcode.
+func (e *Exporter) renderOrgBlock(block graph.Block, orgBlock graph.OrgBlock, embedTrail []string) (string, error) {
+	switch orgBlock.Kind {
+	case graph.OrgBlockComment:
+		return "", nil
+	case graph.OrgBlockSource:
+		return fencedCode(orgBlock.Language(), orgBlock.Body), nil
+	case graph.OrgBlockExample:
+		return fencedCode("", orgBlock.Body), nil
+	case graph.OrgBlockExport:
+		if !strings.EqualFold(orgBlock.Language(), "html") {
+			log.Debugf("Skipping %s export in block %s", orgBlock.Language(), block.ID)
+
+			return "", nil
+		}
+
+		return "\n" + orgBlock.Body + "\n", nil
+	case graph.OrgBlockQuery:
+		return "\n" + e.RenderAdvancedQuery(block, orgBlock.Body) + "\n", nil
+	}
+
+	content, err := e.renderContentNodes(block, orgBlock.Content, embedTrail)
+	if err != nil {
+		return "", err
+	}
+
+	switch orgBlock.Kind {
+	case graph.OrgBlockQuote:
+		lines := strings.Split(strings.Trim(content, "\n"), "\n")
+
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+
+		return "\n" + strings.Join(lines, "\n") + "\n", nil
+	case graph.OrgBlockCenter:
+		return "{{% logseq/center %}}\n" + content + "\n{{% /logseq/center %}}", nil
+	default:
+		return `{{% logseq/callout type="` + orgBlock.Kind + `" %}}` + "\n" + content + "\n{{% /logseq/callout %}}", nil
+	}
+}
+
+// fencedCode wraps code in a fence longer than any backtick run inside it.
+func fencedCode(language string, code string) string {
+	fence := "```"
+
+	for _, run := range backtickRunRe.FindAllString(code, -1) {
+		if len(run) >= len(fence) {
+			fence = run + "`"
+		}
+	}
+
+	return "\n" + fence + language + "\n" + escapeShortcodes(code) + "\n" + fence + "\n"
+}
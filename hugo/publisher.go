@@ -8,8 +8,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gosimple/slug"
 	"github.com/pkg/errors"
@@ -24,21 +26,50 @@ type Exporter struct {
 	PagePermalinks  map[string]string
 	AssetPermalinks map[string]string
 	RequirePublic   bool
+	TaskPolicy      TaskPolicy
+	SiteURL         string         // Prefix for links that leave the site, like calendar entries
+	PublishPDFs     bool           // Copy PDF assets to the site, so annotations can link to them
+	ExportTime      time.Time      // When the export started, so relative query dates agree across pages
+	Location        *time.Location // Time zone of the graph's task timestamps, for dates like "today"
 }
 
+// TaskPolicy determines which task blocks get exported.
+type TaskPolicy string
+
+const (
+	TaskPolicyHide         TaskPolicy = "hide"
+	TaskPolicyShow         TaskPolicy = "show"
+	TaskPolicyShowDoneOnly TaskPolicy = "show-done-only"
+)
+
 const (
 	folderPermissions = 0755
 )
 
-func ExportGraph(graph graph.Graph, siteDir string, requirePublic bool) error {
+// ExportOptions choose what ExportGraph writes.
+type ExportOptions struct {
+	RequirePublic bool           // Only export public pages and blocks
+	TaskPolicy    TaskPolicy     // Which task blocks to export
+	Calendar      bool           // Write scheduled and deadline blocks to static/logseq.ics
+	SiteURL       string         // Base URL of the published site, for links in the calendar
+	PublishPDFs   bool           // Copy PDF assets to the site, so annotations can link to them
+	Location      *time.Location // Time zone of the graph's task timestamps; local time if nil
+}
+
+func ExportGraph(graph graph.Graph, siteDir string, options ExportOptions) error {
 	log.Infof("Exporting from %s to %s", graph.GraphDir, siteDir)
 
-	if requirePublic {
+	if options.RequirePublic {
 		log.Info("Only exporting public blocks")
 
 		graph = graph.PublicGraph()
 	}
 
+	location := options.Location
+	if location == nil {
+		location = time.Local
+	}
+
 	totalPages := len(graph.Pages)
 	totalAssets := len(graph.Assets)
 	log.Infof("Graph has %d pages and %d assets", totalPages, totalAssets)
@@ -50,7 +81,12 @@ func ExportGraph(graph graph.Graph, siteDir string, requirePublic bool) error {
 		ContentDir:      filepath.Join(siteDir, "content"),
 		PagePermalinks:  map[string]string{},
 		AssetPermalinks: map[string]string{},
-		RequirePublic:   requirePublic,
+		RequirePublic:   options.RequirePublic,
+		TaskPolicy:      options.TaskPolicy,
+		SiteURL:         strings.TrimSuffix(options.SiteURL, "/"),
+		PublishPDFs:     options.PublishPDFs,
+		ExportTime:      time.Now().In(location),
+		Location:        location,
 	}
 
 	exporter.PagePermalinks = exporter.SetPagePermalinks()
@@ -69,6 +105,15 @@ func ExportGraph(graph graph.Graph, siteDir string, requirePublic bool) error {
 	}
 
 	log.Infof("Exported %d pages as pages", exportedPageCount)
+
+	if options.Calendar {
+		exportedEntryCount, err := exporter.ExportCalendar()
+		if err != nil {
+			return errors.Wrap(err, "exporting calendar")
+		}
+
+		log.Infof("Exported %d calendar entries", exportedEntryCount)
+	}
 	log.Infof("Exporting assets from %d asset links", len(graph.AssetLinks()))
 
 	exportedAssetCount, err := exporter.ExportAssets()
@@ -92,9 +137,9 @@ func (e *Exporter) ExportAssets() (int, error) {
 	}
 
 	for _, asset := range e.Graph.Assets {
-		log.Debug("Exporting asset " + asset.Name)
-		targetPath := e.PublishedAssetPath(asset.Name)
-		sourcePath := filepath.Join(e.Graph.GraphDir, "assets", asset.Name)
+		log.Debug("Exporting asset " + asset.Path)
+		targetPath := e.PublishedAssetPath(asset.Path)
+		sourcePath := filepath.Join(e.Graph.GraphDir, "assets", filepath.FromSlash(asset.Path))
 		shouldExport, err := e.ShouldExportGraphFile(sourcePath, targetPath)
 
 		if err != nil {
@@ -105,8 +150,13 @@ func (e *Exporter) ExportAssets() (int, error) {
 			continue
 		}
 
+		targetFolder := filepath.Dir(targetPath)
+		if err := os.MkdirAll(targetFolder, folderPermissions); err != nil {
+			return exportCount, errors.Wrap(err, "creating asset folder "+targetFolder)
+		}
+
 		if err := e.ExportGraphFile(sourcePath, targetPath); err != nil {
-			return exportCount, errors.Wrap(err, "exporting asset "+asset.Name)
+			return exportCount, errors.Wrap(err, "exporting asset "+asset.Path)
 		}
 
 		exportCount++
@@ -190,9 +240,18 @@ func (e *Exporter) exportPage(page graph.Page) error {
 	pageFrontmatter := e.determinePageFrontmatter(page)
 	log.Debug("Page frontmatter:", pageFrontmatter)
 
-	pageContent, err := e.ProcessBlock(*page.Root)
-	if err != nil {
-		return errors.Wrap(err, "processing page content")
+	pageContent := ""
+
+	if page.IsWhiteboard() {
+		pageContent = e.ProcessWhiteboard(page)
+	} else {
+		// Start the embed trail with this page, so embeds don't loop back to it.
+		content, err := e.processBlock(*page.Root, []string{embedTrailKey(graph.LinkTypePage, page.Name)})
+		if err != nil {
+			return errors.Wrap(err, "processing page content")
+		}
+
+		pageContent = content
 	}
 
 	if pageContent == "" {
@@ -219,6 +278,10 @@ func (e *Exporter) exportPage(page graph.Page) error {
 		return errors.Wrap(err, "writing content to file")
 	}
 
+	if err := e.exportPageHistory(page); err != nil {
+		return errors.Wrap(err, "exporting page history")
+	}
+
 	return nil
 }
 
@@ -229,7 +292,7 @@ func (e *Exporter) ShouldSkipBlock(block graph.Block) bool {
 		return true
 	}
 
-	if block.IsTask() {
+	if block.IsTask() && !e.ShouldShowTask(*block.Task) {
 		log.Debug("Skipping task block: ", block.String())
 
 		return true
@@ -238,6 +301,18 @@ func (e *Exporter) ShouldSkipBlock(block graph.Block) bool {
 	return false
 }
 
+// ShouldShowTask returns true if the task policy allows exporting a task.
+func (e *Exporter) ShouldShowTask(task graph.Task) bool {
+	switch e.TaskPolicy {
+	case TaskPolicyShow:
+		return true
+	case TaskPolicyShowDoneOnly:
+		return task.IsDone()
+	default:
+		return false
+	}
+}
+
 func (e *Exporter) ConstructBlockShortcode(block graph.Block) string {
 	shortcodeArgs := map[string]string{}
 
@@ -249,27 +324,160 @@ func (e *Exporter) ConstructBlockShortcode(block graph.Block) string {
 			shortcodeArgs["caption"] = strings.Replace(captionProp.Value, "\"", "\\\"", -1)
 		}
 
-		callout := block.Callout()
-		if callout != "" {
-			shortcodeArgs["callout"] = callout
-		}
-
 		if !block.IsPublic() {
 			shortcodeArgs["classes"] = "private"
 		}
+
+		if task := block.Task; task != nil {
+			shortcodeArgs["task"] = string(task.Marker)
+
+			if task.Priority != "" {
+				shortcodeArgs["priority"] = task.Priority
+			}
+
+			if task.Scheduled != nil {
+				shortcodeArgs["scheduled"] = formatTaskTimestamp(*task.Scheduled)
+			}
+
+			if task.Deadline != nil {
+				shortcodeArgs["deadline"] = formatTaskTimestamp(*task.Deadline)
+			}
+		}
 	}
 
 	shortCode := "block"
 
-	for arg, value := range shortcodeArgs {
-		shortCode = shortCode + " " + arg + "=\"" + value + "\""
+	args := make([]string, 0, len(shortcodeArgs))
+	for arg := range shortcodeArgs {
+		args = append(args, arg)
+	}
+
+	sort.Strings(args)
+
+	for _, arg := range args {
+		shortCode = shortCode + " " + arg + "=\"" + shortcodeArgs[arg] + "\""
 	}
 
 	return shortCode
 }
 
+// processText turns Markdown from a block's content into Hugo content, replacing links,
+// queries, embeds, and Logseq markup. Code is kept as written, with shortcodes escaped.
+func (e *Exporter) processText(block graph.Block, text string, embedTrail []string) (string, error) {
+	text, math := ProcessMarkup(text)
+	text, queries := e.ExtractQueries(block, text)
+	processed := strings.Builder{}
+	position := 0
+
+	for _, region := range findTextRegions(text) {
+		processed.WriteString(e.ProcessBlockEmbeddedShortcodes(text[position:region.span.Start]))
+		position = region.span.End
+
+		if region.link == nil {
+			processed.WriteString(escapeShortcodes(text[region.span.Start:region.span.End]))
+
+			continue
+		}
+
+		replacement, err := e.processLink(block, *region.link, embedTrail)
+		if err != nil {
+			return "", err
+		}
+
+		processed.WriteString(replacement)
+	}
+
+	processed.WriteString(e.ProcessBlockEmbeddedShortcodes(text[position:]))
+
+	return InsertMath(InsertQueries(processed.String(), queries), math), nil
+}
+
+// textRegion is a link or a piece of code in text being processed.
+type textRegion struct {
+	span graph.Span
+	link *graph.Link // Nil for code
+}
+
+// findTextRegions returns every link and piece of code in text, in source order.
+// A target linked more than once, or with and without a label, shows up each time.
+func findTextRegions(text string) []textRegion {
+	regions := []textRegion{}
+
+	for _, span := range graph.FindCode(text) {
+		regions = append(regions, textRegion{span: span})
+	}
+
+	for _, link := range graph.FindLinks(text) {
+		regions = append(regions, textRegion{span: *link.Span, link: &link})
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].span.Start < regions[j].span.Start
+	})
+
+	return regions
+}
+
+// processLink replaces a link written in block content with its Hugo content.
+func (e *Exporter) processLink(block graph.Block, link graph.Link, embedTrail []string) (string, error) {
+	link.LinksFrom = block.ID
+
+	if !link.IsEmbed || link.IsAsset() {
+		return e.ProcessBlockLink(link), nil
+	}
+
+	embedded, err := e.ProcessEmbed(block, link, embedTrail)
+	if err != nil {
+		return "", errors.Wrap(err, "processing embed")
+	}
+
+	return embedded, nil
+}
+
+// escapeShortcodes keeps Hugo from reading shortcodes in code.
+func escapeShortcodes(text string) string {
+	return strings.Replace(text, "{{<", "{{/**/<", -1)
+}
+
+// blockText splits block content into a one line summary and the rest, without task markers,
+// planning lines, or reference syntax. It's for places that show a block as plain text.
+func blockText(block graph.Block) (string, string) {
+	text := block.Content.Markdown
+	links := block.Content.LinkOccurrences()
+
+	// Replace from the end, so earlier spans stay put.
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+
+		if link.IsPage() || link.IsTag() || (link.IsBlock() && link.Labelled) {
+			text = text[:link.Span.Start] + link.Label + text[link.Span.End:]
+		}
+	}
+
+	text = graph.StripOrgBlocks(graph.TaskContent(text))
+
+	summary, rest, _ := strings.Cut(text, "\n")
+
+	return strings.TrimSpace(summary), strings.TrimSpace(rest)
+}
+
+// formatTaskTimestamp writes a task date, with the time of day if it has one.
+func formatTaskTimestamp(timestamp graph.TaskTimestamp) string {
+	if timestamp.HasTime {
+		return timestamp.Time.Format(time.RFC3339)
+	}
+
+	return timestamp.Time.Format("2006-01-02")
+}
+
 // ProcessBlock turns a block and its children into Hugo content.
 func (e *Exporter) ProcessBlock(block graph.Block) (string, error) {
+	return e.processBlock(block, []string{embedTrailKey(graph.LinkTypePage, block.PageName)})
+}
+
+// processBlock turns a block and its children into Hugo content.
+// embedTrail lists the pages and blocks being embedded, to catch embed cycles.
+func (e *Exporter) processBlock(block graph.Block, embedTrail []string) (string, error) {
 	log.Debug("Processing block ", block.ID)
 
 	if e.ShouldSkipBlock(block) {
@@ -282,16 +490,23 @@ func (e *Exporter) ProcessBlock(block graph.Block) (string, error) {
 		// root block technically has no content. Only process children.
 		blockContent = block.Content.Markdown
 
-		if block.Content.IsCodeBlock() {
-			blockContent = strings.Replace(blockContent, "{{<", "{{/**/<", -1)
-		} else {
-			blockContent = e.ProcessBlockEmbeddedShortcodes(blockContent)
+		if block.IsTask() {
+			// Task details go in the block shortcode.
+			blockContent = graph.TaskContent(blockContent)
+		}
+
+		nodes, err := graph.ParseOrgBlocks(blockContent)
+		if err != nil {
+			return "", errors.Wrap(err, "parsing org blocks")
+		}
+
+		blockContent, err = e.renderContentNodes(block, nodes, embedTrail)
+		if err != nil {
+			return "", err
 		}
 
-		// process page links
-		for _, link := range block.Links() {
-			replacement := e.ProcessBlockLink(link)
-			blockContent = strings.Replace(blockContent, link.Raw, replacement, -1)
+		if annotation := block.Annotation(); annotation != nil {
+			blockContent = e.renderAnnotation(block, *annotation, blockContent)
 		}
 	}
 
@@ -305,7 +520,7 @@ func (e *Exporter) ProcessBlock(block graph.Block) (string, error) {
 	processedContent := "\n{{% " + shortCode + " %}}" + blockContent
 
 	for _, childBlock := range block.Children {
-		childContent, err := e.ProcessBlock(*childBlock)
+		childContent, err := e.processBlock(*childBlock, embedTrail)
 		if err != nil {
 			return "", errors.Wrap(err, "processing child block")
 		}
@@ -339,14 +554,22 @@ func (e *Exporter) ProcessBlockLink(link graph.Link) string {
 		blockContent := targetBlock.Content.Markdown
 		permalink := e.PermalinkForBlock(*targetBlock)
 
+		if annotation := targetBlock.Annotation(); annotation != nil {
+			blockContent = annotationReference(*targetBlock, *annotation)
+		}
+
+		// A labelled reference like [label](((uuid))) shows its label instead of the block.
+		if link.Labelled {
+			blockContent = link.Label
+		}
+
 		return `{{< block-link link="` + permalink + `" >}}` + blockContent + "{{< /block-link >}}"
 	}
 
 	if link.LinkType == graph.LinkTypeTag {
 		permalink, ok := e.PermalinkForPage(link.LinkPath)
 		if ok {
-			// oops that space from identifying tags
-			shortCode := fmt.Sprintf(` {{< logseq/tag-link label="%s" link="%s" >}}`, link.Label, permalink)
+			shortCode := fmt.Sprintf(`{{< logseq/tag-link label="%s" link="%s" >}}`, link.Label, permalink)
 
 			return shortCode
 		}
@@ -422,6 +645,8 @@ func (e *Exporter) ExportGraphFile(sourcePath string, targetPath string) error {
 
 func (e *Exporter) determinePageFrontmatter(page graph.Page) string {
 	date := ""
+	lastmod := ""
+	history := ""
 	backlinks := []string{}
 	banner := ""
 	summary := ""
@@ -502,12 +727,24 @@ func (e *Exporter) determinePageFrontmatter(page graph.Page) string {
 	dateProp, ok := page.Root.Properties.Get("date")
 	if ok {
 		date = dateProp.String()
+	} else if page.JournalDay != "" {
+		date = page.JournalDay
 	} else if page.IsJournal() {
 		date = page.Name
+	} else if page.CreatedAt != nil {
+		date = page.CreatedAt.Format(time.RFC3339)
 	}
 
 	date = strings.Replace(date, "/", "-", -1)
 
+	if page.UpdatedAt != nil {
+		lastmod = page.UpdatedAt.Format(time.RFC3339)
+	}
+
+	if len(page.History) > 0 {
+		history, _ = e.PermalinkForHistory(page)
+	}
+
 	bannerProp, ok := page.Root.Properties.Get("banner")
 	if ok {
 		bannerPath := strings.TrimPrefix(bannerProp.String(), "../assets/")
@@ -527,19 +764,25 @@ func (e *Exporter) determinePageFrontmatter(page graph.Page) string {
 	frontmatter := struct {
 		Title     string   `json:"title"`
 		Date      string   `json:"date,omitempty"`
+		Lastmod   string   `json:"lastmod,omitempty"`
+		History   string   `json:"history,omitempty"`
 		Backlinks []string `json:"backlinks,omitempty"`
 		Tags      []string `json:"tags,omitempty"`
 		TagLinks  []string `json:"taglinks,omitempty"`
 		Banner    string   `json:"banner,omitempty"`
 		Summary   string   `json:"summary,omitempty"`
+		PDF       string   `json:"pdf,omitempty"`
 	}{
 		Title:     page.Title,
 		Date:      date,
+		Lastmod:   lastmod,
+		History:   history,
 		Backlinks: backlinks,
 		Tags:      tagList,
 		TagLinks:  tagLinks,
 		Banner:    banner,
 		Summary:   summary,
+		PDF:       e.annotatedPDFLink(page),
 	}
 
 	// encode the frontmatter to JSON
@@ -554,13 +797,18 @@ func (e *Exporter) determinePageFrontmatter(page graph.Page) string {
 	return string(frontmatterBytes)
 }
 
-// SetAssetPermalinks builds a map of asset names to permalinks.
+// SetAssetPermalinks builds a map of asset paths to permalinks.
 func (e *Exporter) SetAssetPermalinks() map[string]string {
 	permalinks := map[string]string{}
 
 	for _, asset := range e.Graph.Assets {
-		nameKey := strings.ToLower(asset.Name)
-		permalinks[nameKey] = "/graph-assets/" + asset.Name
+		// Unpublished PDFs have nowhere to link to.
+		if isPDF(asset.Path) && !e.PublishPDFs {
+			continue
+		}
+
+		pathKey := strings.ToLower(asset.Path)
+		permalinks[pathKey] = "/graph-assets/" + asset.Path
 	}
 
 	return permalinks
@@ -582,10 +830,11 @@ func (e *Exporter) SetPagePermalinks() map[string]string {
 
 		if !e.Graph.PageIsHoisted(page) {
 			section := "pages"
-			dateRe := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
 
-			if dateRe.MatchString(page.Name) {
+			if page.IsJournal() {
 				section = "journals"
+			} else if page.IsWhiteboard() {
+				section = "whiteboards"
 			}
 
 			slugSteps = append(slugSteps, section)
@@ -609,13 +858,13 @@ func (e *Exporter) SetPagePermalinks() map[string]string {
 	return permalinks
 }
 
-// PermalinkForAsset determines the permalink for an Asset.
-func (e *Exporter) PermalinkForAsset(assetName string) (string, bool) {
-	nameKey := strings.ToLower(assetName)
-	permalink, ok := e.AssetPermalinks[nameKey]
+// PermalinkForAsset determines the permalink for an Asset by its path in the assets folder.
+func (e *Exporter) PermalinkForAsset(assetPath string) (string, bool) {
+	pathKey := strings.ToLower(assetPath)
+	permalink, ok := e.AssetPermalinks[pathKey]
 
 	if !ok {
-		log.Debug("No permalink found for asset:", assetName)
+		log.Debug("No permalink found for asset:", assetPath)
 	}
 
 	return permalink, ok
@@ -673,16 +922,13 @@ func (e *Exporter) PageContentPath(page graph.Page) string {
 	return contentPath
 }
 
-func (e *Exporter) PublishedAssetPath(assetName string) string {
-	assetBase := filepath.Base(assetName)
-
-	return filepath.Join(e.AssetDir, assetBase)
+// PublishedAssetPath mirrors an asset's path in the graph assets folder under the site asset folder.
+func (e *Exporter) PublishedAssetPath(assetPath string) string {
+	return filepath.Join(e.AssetDir, filepath.FromSlash(assetPath))
 }
 
 func (e *Exporter) ShouldExportGraphFile(sourcePath string, targetPath string) (bool, error) {
-	fileExt := filepath.Ext(sourcePath)
-
-	if fileExt == ".pdf" {
+	if isPDF(sourcePath) && !e.PublishPDFs {
 		log.Info("Skip PDF asset: ", sourcePath)
 
 		return false, nil
@@ -0,0 +1,80 @@
+package hugo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/graph/graphtest"
+)
+
+func TestExporter_ProcessBlock_LabelledAndPlainLinks(t *testing.T) {
+	linkTests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			"plain first",
+			"[[Other]] and [the doc]([[Other]])",
+			`{{< page-link link="/pages/other" >}}Other{{< /page-link >}} and ` +
+				`{{< page-link link="/pages/other" >}}the doc{{< /page-link >}}`,
+		},
+		{
+			"labelled first",
+			"[the doc]([[Other]]) and [[Other]]",
+			`{{< page-link link="/pages/other" >}}the doc{{< /page-link >}} and ` +
+				`{{< page-link link="/pages/other" >}}Other{{< /page-link >}}`,
+		},
+	}
+
+	for _, tt := range linkTests {
+		g := graph.NewGraph()
+		page := graphtest.AddPage(t, &g, "Source", nil, tt.markdown)
+		graphtest.AddPage(t, &g, "Other", nil, "the other page")
+
+		content, err := newExporter(g).ProcessBlock(*page.Root)
+
+		require.NoError(t, err, tt.name)
+		assert.Contains(t, content, tt.want, tt.name)
+		assert.NotContains(t, content, "[[", tt.name)
+	}
+}
+
+func TestExporter_ProcessBlock_Code(t *testing.T) {
+	codeTests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			"inline code",
+			"Dr. Smith wrote `[[Other]]` and [[Other]]",
+			"Dr. Smith wrote `[[Other]]` and {{< page-link link=\"/pages/other\" >}}Other{{< /page-link >}}",
+		},
+		{
+			"tag and page link",
+			"#Other and [[Other]]",
+			`{{< logseq/tag-link label="Other" link="/pages/other" >}} and ` +
+				`{{< page-link link="/pages/other" >}}Other{{< /page-link >}}`,
+		},
+		{
+			"next to a code fence",
+			"See [[Other]]\n```\n{{< shortcode >}} [[Other]]\n```",
+			"See {{< page-link link=\"/pages/other\" >}}Other{{< /page-link >}}\n```\n{{/**/< shortcode >}} [[Other]]\n```",
+		},
+	}
+
+	for _, tt := range codeTests {
+		g := graph.NewGraph()
+		page := graphtest.AddPage(t, &g, "Source", nil, tt.markdown)
+		graphtest.AddPage(t, &g, "Other", nil, "the other page")
+
+		content, err := newExporter(g).ProcessBlock(*page.Root)
+
+		require.NoError(t, err, tt.name)
+		assert.Contains(t, content, tt.want, tt.name)
+	}
+}
@@ -0,0 +1,173 @@
+package hugo
+
+import (
+	"fmt"
+	"strings"
+
+	"export-logseq/graph"
+	"export-logseq/query"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// queryPlaceholder stands in for a rendered query while block links are processed,
+// so links in the query text aren't replaced.
+func queryPlaceholder(index int) string {
+	return fmt.Sprintf("\x00query-%d\x00", index)
+}
+
+// ExtractQueries renders the {{query}} macros in block content, leaving alone the ones in code.
+// It returns the content with a placeholder in place of each query, and the rendered results
+// to put back with InsertQueries.
+func (e *Exporter) ExtractQueries(block graph.Block, content string) (string, []string) {
+	macros := graph.FindQueryMacros(content)
+	rendered := make([]string, len(macros))
+
+	// Replace from the end, so earlier spans stay put.
+	for i := len(macros) - 1; i >= 0; i-- {
+		macro := macros[i]
+		rendered[i] = e.RenderQuery(block, macro.Text)
+		content = content[:macro.Span.Start] + queryPlaceholder(i) + content[macro.Span.End:]
+	}
+
+	return content, rendered
+}
+
+// InsertQueries replaces query placeholders with their rendered results.
+func InsertQueries(content string, rendered []string) string {
+	for i, result := range rendered {
+		content = strings.Replace(content, queryPlaceholder(i), result, 1)
+	}
+
+	return content
+}
+
+// RenderQuery runs a simple query against the graph and renders its results.
+func (e *Exporter) RenderQuery(block graph.Block, text string) string {
+	queryArg := `query="` + shortcodeEscape(strings.TrimSpace(text)) + `"`
+
+	q, err := query.Parse(text)
+	if err != nil {
+		log.Warnf("Can't parse query in block %s: %s", block.ID, err)
+
+		return queryErrorShortcode(queryArg, err)
+	}
+
+	return e.renderQueryResult(block, queryArg, q.Run(&e.Graph, e.ExportTime))
+}
+
+// RenderAdvancedQuery runs the EDN of a #+BEGIN_QUERY block against the graph and renders its results.
+// Queries using forms that aren't supported get a warning and an error placeholder.
+func (e *Exporter) RenderAdvancedQuery(block graph.Block, text string) string {
+	q, err := query.ParseAdvanced(text)
+	if err != nil {
+		log.Warnf("Unsupported advanced query in block %s: %s", block.ID, err)
+
+		return queryErrorShortcode(`advanced="true"`, err)
+	}
+
+	result, err := q.Run(&e.Graph, e.ExportTime, block.PageName)
+	if err != nil {
+		log.Warnf("Can't run advanced query in block %s: %s", block.ID, err)
+
+		return queryErrorShortcode(`advanced="true"`, err)
+	}
+
+	args := `advanced="true"`
+	if q.Title != "" {
+		args += ` title="` + shortcodeEscape(q.Title) + `"`
+	}
+
+	return e.renderQueryResult(block, args, result)
+}
+
+func queryErrorShortcode(args string, err error) string {
+	return `{{< logseq/query ` + args + ` error="` + shortcodeEscape(err.Error()) + `" >}}{{< /logseq/query >}}`
+}
+
+func shortcodeEscape(value string) string {
+	value = strings.Replace(value, "\n", " ", -1)
+
+	return strings.Replace(value, "\"", "\\\"", -1)
+}
+
+// renderQueryResult renders query results as a list of links, or as a table if the block
+// has the query-table property, inside a logseq/query shortcode.
+func (e *Exporter) renderQueryResult(block graph.Block, args string, result query.Result) string {
+	asTable := false
+
+	if tableProp, ok := block.Properties.Get("query-table"); ok {
+		asTable = tableProp.Bool()
+	}
+
+	rows := [][]string{}
+	header := []string{"Page"}
+
+	for _, page := range result.Pages {
+		if e.RequirePublic && !page.IsPublic() {
+			continue
+		}
+
+		rows = append(rows, []string{e.queryPageLink(page.Name)})
+	}
+
+	if len(result.Blocks) > 0 {
+		header = []string{"Block", "Page"}
+
+		for i := range rows {
+			rows[i] = append(rows[i], "")
+		}
+
+		for _, resultBlock := range result.Blocks {
+			if e.ShouldSkipBlock(*resultBlock) {
+				continue
+			}
+
+			summary, _ := blockText(*resultBlock)
+			summary = strings.Replace(summary, "|", "\\|", -1)
+			blockLink := `{{< block-link link="` + e.PermalinkForBlock(*resultBlock) + `" >}}` + summary + "{{< /block-link >}}"
+			rows = append(rows, []string{blockLink, e.queryPageLink(resultBlock.PageName)})
+		}
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, `{{%% logseq/query %s count="%d" %%}}`, args, len(rows))
+	sb.WriteString("\n\n")
+
+	if asTable && len(rows) > 0 {
+		sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+		sb.WriteString(strings.Repeat("| --- ", len(header)) + "|\n")
+
+		for _, row := range rows {
+			sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+	} else {
+		for _, row := range rows {
+			if len(row) > 1 && row[1] != "" {
+				sb.WriteString("- " + row[0] + " (" + row[1] + ")\n")
+			} else {
+				sb.WriteString("- " + row[0] + "\n")
+			}
+		}
+	}
+
+	sb.WriteString("\n{{% /logseq/query %}}")
+
+	return sb.String()
+}
+
+func (e *Exporter) queryPageLink(pageName string) string {
+	title := pageName
+
+	if page, err := e.Graph.FindPage(pageName); err == nil && page.Title != "" {
+		title = page.Title
+	}
+
+	permalink, ok := e.PermalinkForPage(pageName)
+	if !ok {
+		return UnavailableLink(title)
+	}
+
+	return `{{< page-link link="` + permalink + `" >}}` + strings.Replace(title, "|", "\\|", -1) + "{{< /page-link >}}"
+}
@@ -0,0 +1,137 @@
+package hugo
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"export-logseq/graph"
+)
+
+const (
+	whiteboardMargin      = 20.0
+	whiteboardLineHeight  = 20.0
+	whiteboardStroke      = "#333333"
+	whiteboardPortalFill  = "#f5f5f5"
+	whiteboardStrokeWidth = 2
+)
+
+// ProcessWhiteboard turns a whiteboard page into Hugo content:
+// an SVG drawing of its shapes followed by the pages and blocks it references.
+func (e *Exporter) ProcessWhiteboard(page graph.Page) string {
+	svg := RenderWhiteboardSVG(page.Whiteboard)
+	content := "\n{{< logseq/whiteboard >}}\n" + svg + "\n{{< /logseq/whiteboard >}}\n"
+
+	references := []string{}
+	seen := map[string]bool{}
+
+	for _, link := range page.Links() {
+		if !link.IsPage() && !link.IsBlock() && !link.IsTag() {
+			continue
+		}
+
+		reference := "- " + strings.TrimSpace(e.ProcessBlockLink(link))
+		if seen[reference] {
+			continue
+		}
+
+		seen[reference] = true
+		references = append(references, reference)
+	}
+
+	if len(references) > 0 {
+		sort.Strings(references)
+		content = content + "\n## Referenced pages\n\n" + strings.Join(references, "\n") + "\n"
+	}
+
+	return content
+}
+
+// RenderWhiteboardSVG draws whiteboard shapes and text as a standalone SVG image.
+func RenderWhiteboardSVG(whiteboard *graph.Whiteboard) string {
+	topLeft, bottomRight := whiteboard.Bounds()
+	originX, originY := topLeft.X-whiteboardMargin, topLeft.Y-whiteboardMargin
+	width := bottomRight.X - topLeft.X + 2*whiteboardMargin
+	height := bottomRight.Y - topLeft.Y + 2*whiteboardMargin
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" class="whiteboard" viewBox="%g %g %g %g">`,
+		originX, originY, width, height)
+
+	for _, shape := range whiteboard.Shapes {
+		sb.WriteString("\n")
+		sb.WriteString(renderShape(shape))
+	}
+
+	sb.WriteString("\n</svg>")
+
+	return sb.String()
+}
+
+func shapeColor(color string, fallback string) string {
+	if strings.HasPrefix(color, "#") {
+		return color
+	}
+
+	return fallback
+}
+
+func renderShape(shape graph.WhiteboardShape) string {
+	stroke := shapeColor(shape.Stroke, whiteboardStroke)
+	fill := shapeColor(shape.Fill, "none")
+	style := fmt.Sprintf(`stroke="%s" fill="%s" stroke-width="%d"`, stroke, fill, whiteboardStrokeWidth)
+	element := ""
+
+	switch shape.Type {
+	case graph.ShapeTypeText:
+		return renderShapeText(shape, shape.X, shape.Y+whiteboardLineHeight)
+	case graph.ShapeTypeEllipse:
+		element = fmt.Sprintf(`<ellipse cx="%g" cy="%g" rx="%g" ry="%g" %s/>`,
+			shape.X+shape.Width/2, shape.Y+shape.Height/2, shape.Width/2, shape.Height/2, style)
+	case graph.ShapeTypeLine, graph.ShapeTypePencil, graph.ShapeTypeHighlight:
+		points := []string{}
+
+		for _, point := range shape.Points {
+			points = append(points, fmt.Sprintf("%g,%g", shape.X+point.X, shape.Y+point.Y))
+		}
+
+		style = fmt.Sprintf(`stroke="%s" fill="none" stroke-width="%d"`, stroke, whiteboardStrokeWidth)
+		element = fmt.Sprintf(`<polyline points="%s" %s/>`, strings.Join(points, " "), style)
+	case graph.ShapeTypePortal:
+		portalStyle := fmt.Sprintf(`stroke="%s" fill="%s" stroke-width="1"`, stroke, whiteboardPortalFill)
+		element = fmt.Sprintf(`<rect x="%g" y="%g" width="%g" height="%g" rx="8" %s/>`,
+			shape.X, shape.Y, shape.Width, shape.Height, portalStyle)
+		shape.Text = shape.PortalTarget
+	default:
+		element = fmt.Sprintf(`<rect x="%g" y="%g" width="%g" height="%g" %s/>`,
+			shape.X, shape.Y, shape.Width, shape.Height, style)
+	}
+
+	if shape.Text == "" {
+		return element
+	}
+
+	return element + "\n" + renderShapeText(shape, shape.X+whiteboardMargin/2, shape.Y+whiteboardLineHeight)
+}
+
+// renderShapeText writes a shape's text with one tspan per line.
+func renderShapeText(shape graph.WhiteboardShape, x float64, y float64) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, `<text x="%g" y="%g" fill="%s">`, x, y, shapeColor(shape.Stroke, whiteboardStroke))
+
+	for i, line := range strings.Split(shape.Text, "\n") {
+		dy := 0.0
+		if i > 0 {
+			dy = whiteboardLineHeight
+		}
+
+		fmt.Fprintf(&sb, `<tspan x="%g" dy="%g">%s</tspan>`, x, dy, html.EscapeString(line))
+	}
+
+	sb.WriteString("</text>")
+
+	return sb.String()
+}
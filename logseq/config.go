@@ -0,0 +1,80 @@
+package logseq
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"olympos.io/encoding/edn"
+
+	"export-logseq/graph"
+)
+
+const configPath = "logseq/config.edn"
+
+// configEDN mirrors the parts of logseq/config.edn that the loader cares about.
+type configEDN struct {
+	PagesDirectory         string      `edn:"pages-directory"`
+	JournalsDirectory      string      `edn:"journals-directory"`
+	WhiteboardsDirectory   string      `edn:"whiteboards-directory"`
+	FileNameFormat         edn.Keyword `edn:"file/name-format"`
+	JournalPageTitleFormat string      `edn:"journal/page-title-format"`
+	JournalFileNameFormat  string      `edn:"journal/file-name-format"`
+	Hidden                 []string    `edn:"hidden"`
+	IgnoredFiles           []string    `edn:"export/ignored-files"`
+}
+
+// LoadConfig reads logseq/config.edn from a graph directory.
+// A graph without a config file gets Logseq's defaults.
+func LoadConfig(graphDir string) (graph.Config, error) {
+	configFile := filepath.Join(graphDir, configPath)
+	file, err := os.Open(configFile)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Warn("No config file found, using defaults: ", configFile)
+
+			return graph.NewConfig(), nil
+		}
+
+		return graph.Config{}, errors.Wrap(err, "opening config file")
+	}
+
+	defer file.Close()
+
+	return ReadConfig(file)
+}
+
+// ReadConfig parses Logseq config EDN, filling in defaults for missing settings.
+func ReadConfig(r io.Reader) (graph.Config, error) {
+	config := graph.NewConfig()
+	raw := configEDN{
+		PagesDirectory:         config.PagesDirectory,
+		JournalsDirectory:      config.JournalsDirectory,
+		WhiteboardsDirectory:   config.WhiteboardsDirectory,
+		FileNameFormat:         edn.Keyword(config.FileNameFormat),
+		JournalPageTitleFormat: config.JournalPageTitleFormat,
+		JournalFileNameFormat:  config.JournalFileNameFormat,
+		Hidden:                 config.Hidden,
+		IgnoredFiles:           config.IgnoredFiles,
+	}
+
+	if err := edn.NewDecoder(r).Decode(&raw); err != nil {
+		return graph.Config{}, errors.Wrap(err, "decoding config EDN")
+	}
+
+	config.PagesDirectory = raw.PagesDirectory
+	config.JournalsDirectory = raw.JournalsDirectory
+	config.WhiteboardsDirectory = raw.WhiteboardsDirectory
+	config.FileNameFormat = graph.FileNameFormat(raw.FileNameFormat)
+	config.JournalPageTitleFormat = raw.JournalPageTitleFormat
+	config.JournalFileNameFormat = raw.JournalFileNameFormat
+	config.Hidden = raw.Hidden
+	config.IgnoredFiles = raw.IgnoredFiles
+
+	log.Debugf("Loaded config: %+v", config)
+
+	return config, nil
+}
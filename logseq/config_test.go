@@ -0,0 +1,46 @@
+package logseq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/logseq"
+)
+
+func TestConfig_ReadConfig(t *testing.T) {
+	configEDN := `;; Logseq config
+{:meta/version 1
+ :hidden ["/archived"]
+ :file/name-format :triple-lowbar
+ :journal/page-title-format "yyyy-MM-dd"
+ :journals-directory "daily"
+ :ui/show-brackets? true
+ :default-queries {:journals []}}`
+
+	config, err := logseq.ReadConfig(strings.NewReader(configEDN))
+
+	require.NoError(t, err)
+	assert.Equal(t, graph.FileNameFormatTripleLowbar, config.FileNameFormat)
+	assert.Equal(t, "yyyy-MM-dd", config.JournalPageTitleFormat)
+	assert.Equal(t, "daily", config.JournalsDirectory)
+	assert.Equal(t, "pages", config.PagesDirectory)
+	assert.Equal(t, []string{"/archived"}, config.Hidden)
+	assert.Equal(t, []string{"Templates.md"}, config.IgnoredFiles)
+}
+
+func TestConfig_ReadConfig_Invalid(t *testing.T) {
+	_, err := logseq.ReadConfig(strings.NewReader("{:hidden"))
+
+	assert.Error(t, err)
+}
+
+func TestConfig_LoadConfig_MissingFile(t *testing.T) {
+	config, err := logseq.LoadConfig(t.TempDir())
+
+	require.NoError(t, err)
+	assert.Equal(t, graph.NewConfig(), config)
+}
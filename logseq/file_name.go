@@ -0,0 +1,84 @@
+package logseq
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"export-logseq/graph"
+)
+
+// PageNameFromFileName decodes a page file name (without extension) into a page name.
+//
+// The triple-lowbar format separates namespaces with "___" and percent-encodes reserved
+// characters. The legacy format also reads "___" separators. Older legacy files either
+// percent-encode the separator as "%2F" and keep dots as they are, or separate namespaces
+// with "." between words, so version numbers like "v1.2" keep their dots.
+func PageNameFromFileName(fileNameBody string, format graph.FileNameFormat) string {
+	if format == graph.FileNameFormatTripleLowbar || strings.Contains(fileNameBody, "___") {
+		return safeURLDecode(strings.ReplaceAll(fileNameBody, "___", "/"))
+	}
+
+	if isLegacyURLFileName(fileNameBody) {
+		return safeURLDecode(fileNameBody)
+	}
+
+	return safeURLDecode(legacyDotSeparators(fileNameBody))
+}
+
+// isLegacyURLFileName returns true if a legacy file name encodes namespaces as "%2F".
+func isLegacyURLFileName(fileNameBody string) bool {
+	return strings.Contains(strings.ToUpper(fileNameBody), "%2F")
+}
+
+// legacyDotSeparators turns the dots separating namespaces in a legacy file name into "/".
+// Leading, trailing, and repeated dots, and dots between digits, are part of the name.
+func legacyDotSeparators(fileNameBody string) string {
+	runes := []rune(fileNameBody)
+
+	for i := 1; i < len(runes)-1; i++ {
+		if runes[i] != '.' {
+			continue
+		}
+
+		before, after := runes[i-1], runes[i+1]
+
+		if before == '.' || after == '.' || before == '/' || after == '/' {
+			continue
+		}
+
+		if unicode.IsDigit(before) && unicode.IsDigit(after) {
+			continue
+		}
+
+		runes[i] = '/'
+	}
+
+	return string(runes)
+}
+
+// safeURLDecode decodes percent-encoded text, returning it unchanged if it isn't valid.
+func safeURLDecode(text string) string {
+	if !strings.Contains(text, "%") {
+		return text
+	}
+
+	decoded, err := url.PathUnescape(text)
+	if err != nil {
+		return text
+	}
+
+	return decoded
+}
+
+// PageNameCollisionError is returned when two files in a graph resolve to the same page name.
+type PageNameCollisionError struct {
+	PageName     string
+	File         string
+	ExistingFile string
+}
+
+func (e PageNameCollisionError) Error() string {
+	return fmt.Sprintf("page name %q from %s is already used by %s", e.PageName, e.File, e.ExistingFile)
+}
@@ -0,0 +1,39 @@
+package logseq_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/graph"
+	"export-logseq/logseq"
+)
+
+func TestFileName_PageNameFromFileName(t *testing.T) {
+	fileNameTests := []struct {
+		body   string
+		format graph.FileNameFormat
+		want   string
+	}{
+		{"Page", graph.FileNameFormatTripleLowbar, "Page"},
+		{"post___Hello World", graph.FileNameFormatTripleLowbar, "post/Hello World"},
+		{"What%3F", graph.FileNameFormatTripleLowbar, "What?"},
+		{"v1.2", graph.FileNameFormatTripleLowbar, "v1.2"},
+		{"post.Hello World", graph.FileNameFormatLegacy, "post/Hello World"},
+		{"post%2FHello", graph.FileNameFormatLegacy, "post/Hello"},
+		{"v1.2%2fnotes", graph.FileNameFormatLegacy, "v1.2/notes"},
+		{"What%3F Why%3A", graph.FileNameFormatLegacy, "What? Why:"},
+		{"100%25", graph.FileNameFormatTripleLowbar, "100%"},
+		{"100%", graph.FileNameFormatLegacy, "100%"},
+		{"post___Hello World", graph.FileNameFormatLegacy, "post/Hello World"},
+		{"releases___v1.2", graph.FileNameFormatLegacy, "releases/v1.2"},
+		{"v1.2", graph.FileNameFormatLegacy, "v1.2"},
+		{"Python 3.12.Notes", graph.FileNameFormatLegacy, "Python 3.12/Notes"},
+		{".hidden", graph.FileNameFormatLegacy, ".hidden"},
+		{"Wait...", graph.FileNameFormatLegacy, "Wait..."},
+	}
+
+	for _, tt := range fileNameTests {
+		assert.Equal(t, tt.want, logseq.PageNameFromFileName(tt.body, tt.format), tt.body)
+	}
+}
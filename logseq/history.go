@@ -0,0 +1,223 @@
+package logseq
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"export-logseq/graph"
+)
+
+// Folders where Logseq keeps older copies of page files.
+var historyDirs = []string{"logseq/bak", "logseq/version-files"}
+
+// Backup file names start with the time they were saved, like 2024-06-03T10_20_30.123Z.Desktop.md.
+var snapshotTimeRe = regexp.MustCompile(`^(\d{4})[-_](\d{2})[-_](\d{2})[T_](\d{2})[-_:](\d{2})[-_:](\d{2})(?:\.(\d{1,3}))?`)
+
+// pageSnapshot is an older copy of a page file.
+type pageSnapshot struct {
+	File      string
+	Source    string
+	Timestamp time.Time
+}
+
+// LoadHistory finds saved copies of page files in logseq/bak and logseq/version-files,
+// and records each page's history as block-level changes, ending with the current page.
+// Snapshots that can't be read are skipped.
+func LoadHistory(g *graph.Graph) error {
+	pageKeys := historyPageKeys(g)
+	snapshots := map[string][]pageSnapshot{}
+
+	for _, historyDir := range historyDirs {
+		historyRoot := filepath.Join(g.GraphDir, filepath.FromSlash(historyDir))
+		log.Infof("Loading page history from %s", historyRoot)
+
+		err := filepath.WalkDir(historyRoot, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && file == historyRoot {
+					return filepath.SkipDir
+				}
+
+				return err
+			}
+
+			extension := filepath.Ext(file)
+			if !entry.Type().IsRegular() || (extension != ".md" && extension != orgExtension) {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(historyRoot, file)
+			if err != nil {
+				return errors.Wrap(err, "calculating snapshot path")
+			}
+
+			nameKey, ok := snapshotPageKey(filepath.ToSlash(relPath), pageKeys)
+			if !ok {
+				log.Debug("No page found for snapshot: ", file)
+
+				return nil
+			}
+
+			snapshot := pageSnapshot{
+				File:   file,
+				Source: path.Join(historyDir, filepath.ToSlash(relPath)),
+			}
+
+			snapshot.Timestamp, ok = snapshotTime(filepath.Base(file))
+			if !ok {
+				info, err := entry.Info()
+				if err != nil {
+					return errors.Wrap(err, "reading snapshot file info")
+				}
+
+				snapshot.Timestamp = info.ModTime().UTC()
+			}
+
+			snapshots[nameKey] = append(snapshots[nameKey], snapshot)
+
+			return nil
+		})
+
+		if err != nil {
+			return errors.Wrap(err, "walking "+historyRoot)
+		}
+	}
+
+	for nameKey, pageSnapshots := range snapshots {
+		page := g.Pages[nameKey]
+		page.History = pageHistory(page, pageSnapshots)
+	}
+
+	return nil
+}
+
+// historyPageKeys maps page file paths relative to the graph directory, without extensions,
+// to the lowercased names of the pages loaded from them.
+func historyPageKeys(g *graph.Graph) map[string]string {
+	pageKeys := map[string]string{}
+
+	for nameKey, page := range g.Pages {
+		if page.IsPlaceholder() || page.IsWhiteboard() {
+			continue
+		}
+
+		pagesDir := g.Config.PagesDirectory
+		if page.JournalDay != "" {
+			pagesDir = g.Config.JournalsDirectory
+		}
+
+		pathInGraph := path.Join(pagesDir, filepath.ToSlash(page.PathInGraph))
+		pageKeys[strings.TrimSuffix(pathInGraph, path.Ext(pathInGraph))] = nameKey
+	}
+
+	return pageKeys
+}
+
+// snapshotPageKey finds the page a snapshot belongs to.
+// Backups live in a folder named after the page file, while version files mirror the
+// page file's path, sometimes under an extra folder like "base" or "incoming".
+func snapshotPageKey(relPath string, pageKeys map[string]string) (string, bool) {
+	candidate := strings.TrimSuffix(relPath, path.Ext(relPath))
+
+	if _, ok := snapshotTime(path.Base(relPath)); ok {
+		candidate = path.Dir(relPath)
+	}
+
+	for {
+		if nameKey, ok := pageKeys[candidate]; ok {
+			return nameKey, true
+		}
+
+		_, rest, found := strings.Cut(candidate, "/")
+		if !found {
+			return "", false
+		}
+
+		candidate = rest
+	}
+}
+
+// snapshotTime reads the save time from a backup file name.
+func snapshotTime(fileName string) (time.Time, bool) {
+	match := snapshotTimeRe.FindStringSubmatch(fileName)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	// Pad fractional seconds out to milliseconds.
+	match[7] = (match[7] + "000")[:3]
+	parts := make([]int, len(match)-1)
+
+	for i, part := range match[1:] {
+		parts[i], _ = strconv.Atoi(part)
+	}
+
+	timestamp := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5],
+		parts[6]*int(time.Millisecond), time.UTC)
+
+	return timestamp, true
+}
+
+// pageHistory orders a page's snapshots and describes each by its changes from the one
+// before, leaving out snapshots that changed nothing.
+func pageHistory(page *graph.Page, snapshots []pageSnapshot) []graph.PageVersion {
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+
+	history := []graph.PageVersion{}
+	previous := []string{}
+	// Snapshots saved before the page was made public still hide its private blocks.
+	publicPage := page.IsPublic()
+
+	for _, snapshot := range snapshots {
+		snapshotPage := graph.Page{Name: page.Name}
+		// Only the text of snapshot blocks is compared, so their task times don't matter.
+		blocks, err := loadPageBlocks(&snapshotPage, snapshot.File, time.Local)
+
+		if err != nil {
+			log.Warn("Skipping snapshot that failed to load: ", err)
+
+			continue
+		}
+
+		snapshotPage.AllBlocks = blocks
+		contents := snapshotPage.BlockContents(publicPage)
+		changes := graph.DiffBlocks(previous, contents)
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		history = append(history, graph.PageVersion{
+			Timestamp: snapshot.Timestamp,
+			Source:    snapshot.Source,
+			Changes:   changes,
+		})
+		previous = contents
+	}
+
+	if len(history) == 0 {
+		return history
+	}
+
+	current := graph.PageVersion{Source: "current", Changes: graph.DiffBlocks(previous, page.BlockContents(publicPage))}
+	if page.UpdatedAt != nil {
+		current.Timestamp = *page.UpdatedAt
+	}
+
+	if len(current.Changes) > 0 {
+		history = append(history, current)
+	}
+
+	return history
+}
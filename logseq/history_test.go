@@ -0,0 +1,75 @@
+package logseq_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/logseq"
+)
+
+func TestHistory_LoadHistory(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Research.md", "- intro\n- findings, revised\n- conclusion")
+	writeGraphFile(t, graphDir, "pages/Quiet.md", "- nothing saved")
+	writeGraphFile(t, graphDir, "logseq/bak/pages/Research/2024-01-02T03_04_05.678Z.Desktop.md", "- intro")
+	writeGraphFile(t, graphDir, "logseq/bak/pages/Research/2024-02-01T00_00_00.000Z.Desktop.md", "- intro\n- findings")
+	writeGraphFile(t, graphDir, "logseq/version-files/base/pages/Research.md", "- intro\n- findings")
+	writeGraphFile(t, graphDir, "logseq/bak/pages/Unknown/2024-01-02T03_04_05.678Z.Desktop.md", "- orphan")
+
+	baseTime := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	versionFile := filepath.Join(graphDir, "logseq", "version-files", "base", "pages", "Research.md")
+	require.NoError(t, os.Chtimes(versionFile, baseTime, baseTime))
+
+	g, _, err := logseq.LoadGraph(graphDir, false)
+	require.NoError(t, err)
+	require.NoError(t, logseq.LoadHistory(&g))
+
+	quiet, err := g.FindPage("Quiet")
+	require.NoError(t, err)
+	assert.Empty(t, quiet.History)
+
+	research, err := g.FindPage("Research")
+	require.NoError(t, err)
+
+	// The version file matches the backup before it, so it adds nothing to the history.
+	require.Len(t, research.History, 3)
+
+	first := research.History[0]
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 678*int(time.Millisecond), time.UTC), first.Timestamp)
+	assert.Equal(t, "logseq/bak/pages/Research/2024-01-02T03_04_05.678Z.Desktop.md", first.Source)
+	assert.Equal(t, []graph.BlockChange{{Type: graph.BlockAdded, After: "intro"}}, first.Changes)
+
+	second := research.History[1]
+	assert.Equal(t, []graph.BlockChange{{Type: graph.BlockAdded, After: "findings"}}, second.Changes)
+
+	current := research.History[2]
+	assert.Equal(t, "current", current.Source)
+	assert.Equal(t, []graph.BlockChange{
+		{Type: graph.BlockChanged, Before: "findings", After: "findings, revised"},
+		{Type: graph.BlockAdded, After: "conclusion"},
+	}, current.Changes)
+}
+
+func TestHistory_LoadHistory_SnapshotSavedWhilePrivate(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Notes.md", "public:: true\n\n- intro\n- published")
+	writeGraphFile(t, graphDir, "logseq/bak/pages/Notes/2024-01-02T03_04_05.678Z.Desktop.md",
+		"- intro\n- draft\n  public:: false")
+
+	g, _, err := logseq.LoadGraph(graphDir, false)
+	require.NoError(t, err)
+	require.NoError(t, logseq.LoadHistory(&g))
+
+	notes, err := g.FindPage("Notes")
+	require.NoError(t, err)
+	require.Len(t, notes.History, 2)
+
+	assert.Equal(t, []graph.BlockChange{{Type: graph.BlockAdded, After: "intro"}}, notes.History[0].Changes)
+	assert.Equal(t, []graph.BlockChange{{Type: graph.BlockAdded, After: "published"}}, notes.History[1].Changes)
+}
@@ -0,0 +1,199 @@
+package logseq
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Date tokens understood in Logseq journal formats, longest first so that
+// "MMMM" is not read as "MM" twice.
+var journalDateTokens = []string{
+	"yyyy", "EEEE", "MMMM", "EEE", "MMM", "yy", "MM", "dd", "do", "E", "M", "d",
+}
+
+// tokenizeDateFormat splits a Logseq date format into tokens and literal text.
+func tokenizeDateFormat(format string) []string {
+	tokens := []string{}
+	literal := ""
+
+	for i := 0; i < len(format); {
+		matched := ""
+
+		for _, token := range journalDateTokens {
+			if strings.HasPrefix(format[i:], token) {
+				matched = token
+
+				break
+			}
+		}
+
+		if matched == "" {
+			literal += format[i : i+1]
+			i++
+
+			continue
+		}
+
+		if literal != "" {
+			tokens = append(tokens, literal)
+			literal = ""
+		}
+
+		tokens = append(tokens, matched)
+		i += len(matched)
+	}
+
+	if literal != "" {
+		tokens = append(tokens, literal)
+	}
+
+	return tokens
+}
+
+func isDateToken(s string) bool {
+	for _, token := range journalDateTokens {
+		if s == token {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ordinalSuffix(day int) string {
+	if day >= 11 && day <= 13 {
+		return "th"
+	}
+
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+// FormatJournalDate formats a date using a Logseq journal date format.
+func FormatJournalDate(date time.Time, format string) string {
+	var sb strings.Builder
+
+	for _, token := range tokenizeDateFormat(format) {
+		switch token {
+		case "yyyy":
+			sb.WriteString(date.Format("2006"))
+		case "yy":
+			sb.WriteString(date.Format("06"))
+		case "MMMM":
+			sb.WriteString(date.Format("January"))
+		case "MMM":
+			sb.WriteString(date.Format("Jan"))
+		case "MM":
+			sb.WriteString(date.Format("01"))
+		case "M":
+			sb.WriteString(date.Format("1"))
+		case "dd":
+			sb.WriteString(date.Format("02"))
+		case "do":
+			sb.WriteString(fmt.Sprintf("%d%s", date.Day(), ordinalSuffix(date.Day())))
+		case "d":
+			sb.WriteString(date.Format("2"))
+		case "EEEE":
+			sb.WriteString(date.Format("Monday"))
+		case "EEE", "E":
+			sb.WriteString(date.Format("Mon"))
+		default:
+			sb.WriteString(token)
+		}
+	}
+
+	return sb.String()
+}
+
+// ParseJournalDate parses text written in a Logseq journal date format.
+func ParseJournalDate(text string, format string) (time.Time, error) {
+	tokens := tokenizeDateFormat(format)
+	pattern := "^"
+
+	for _, token := range tokens {
+		switch token {
+		case "yyyy":
+			pattern += `(\d{4})`
+		case "yy", "MM", "dd":
+			pattern += `(\d{2})`
+		case "M", "d":
+			pattern += `(\d{1,2})`
+		case "do":
+			pattern += `(\d{1,2})(?:st|nd|rd|th)`
+		case "MMMM", "MMM", "EEEE", "EEE", "E":
+			pattern += `([[:alpha:]]+)`
+		default:
+			pattern += regexp.QuoteMeta(token)
+		}
+	}
+
+	match := regexp.MustCompile(pattern + "$").FindStringSubmatch(text)
+	if match == nil {
+		return time.Time{}, errors.Errorf("%q does not match date format %q", text, format)
+	}
+
+	year, month, day := 0, time.Month(0), 0
+	groups := match[1:]
+
+	for _, token := range tokens {
+		if !isDateToken(token) {
+			continue
+		}
+
+		value := groups[0]
+		groups = groups[1:]
+
+		switch token {
+		case "yyyy":
+			year, _ = strconv.Atoi(value)
+		case "yy":
+			shortYear, _ := strconv.Atoi(value)
+			year = 2000 + shortYear
+		case "MM", "M":
+			monthNumber, _ := strconv.Atoi(value)
+			month = time.Month(monthNumber)
+		case "MMMM", "MMM":
+			parsed, err := parseMonthName(value)
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			month = parsed
+		case "dd", "d", "do":
+			day, _ = strconv.Atoi(value)
+		}
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	if date.Year() != year || date.Month() != month || date.Day() != day {
+		return time.Time{}, errors.Errorf("%q is not a valid date", text)
+	}
+
+	return date, nil
+}
+
+func parseMonthName(name string) (time.Month, error) {
+	for month := time.January; month <= time.December; month++ {
+		longName := month.String()
+
+		if strings.EqualFold(name, longName) || strings.EqualFold(name, longName[:3]) {
+			return month, nil
+		}
+	}
+
+	return 0, errors.Errorf("unknown month name: %s", name)
+}
@@ -0,0 +1,70 @@
+package logseq_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/logseq"
+)
+
+func TestJournalDate_FormatJournalDate(t *testing.T) {
+	date := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)
+	formatTests := []struct {
+		format string
+		want   string
+	}{
+		{"yyyy-MM-dd", "2024-06-03"},
+		{"yyyy_MM_dd", "2024_06_03"},
+		{"MMM do, yyyy", "Jun 3rd, 2024"},
+		{"EEEE, MMMM d, yyyy", "Monday, June 3, 2024"},
+		{"E, dd.MM.yyyy", "Mon, 03.06.2024"},
+		{"yyyy年MM月dd日", "2024年06月03日"},
+	}
+
+	for _, tt := range formatTests {
+		assert.Equal(t, tt.want, logseq.FormatJournalDate(date, tt.format))
+	}
+}
+
+func TestJournalDate_FormatJournalDate_Ordinals(t *testing.T) {
+	ordinalTests := map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 22: "22nd"}
+
+	for day, want := range ordinalTests {
+		date := time.Date(2024, time.March, day, 0, 0, 0, 0, time.UTC)
+
+		assert.Equal(t, want, logseq.FormatJournalDate(date, "do"))
+	}
+}
+
+func TestJournalDate_ParseJournalDate(t *testing.T) {
+	want := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC)
+	parseTests := []struct {
+		text   string
+		format string
+	}{
+		{"2024_06_03", "yyyy_MM_dd"},
+		{"Jun 3rd, 2024", "MMM do, yyyy"},
+		{"Monday, June 3, 2024", "EEEE, MMMM d, yyyy"},
+		{"2024年06月03日", "yyyy年MM月dd日"},
+	}
+
+	for _, tt := range parseTests {
+		got, err := logseq.ParseJournalDate(tt.text, tt.format)
+
+		require.NoError(t, err, tt.text)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestJournalDate_ParseJournalDate_NoMatch(t *testing.T) {
+	invalidTests := []string{"Some Page", "2024_13_01", "2024_02_30"}
+
+	for _, text := range invalidTests {
+		_, err := logseq.ParseJournalDate(text, "yyyy_MM_dd")
+
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,59 @@
+package logseq
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PageFailure records a page file that could not be loaded.
+type PageFailure struct {
+	File string `json:"file"`
+	Err  error  `json:"-"`
+}
+
+// ParseError returns the located parse error behind the failure, if there is one.
+func (f PageFailure) ParseError() (ParseError, bool) {
+	var parseErr ParseError
+	ok := errors.As(f.Err, &parseErr)
+
+	return parseErr, ok
+}
+
+func (f PageFailure) Error() string {
+	return fmt.Sprintf("%s: %v", f.File, f.Err)
+}
+
+// LoadReport collects the pages skipped while loading a graph leniently.
+type LoadReport struct {
+	Failures []PageFailure `json:"failures"`
+}
+
+// NewLoadReport creates an empty load report.
+func NewLoadReport() LoadReport {
+	return LoadReport{
+		Failures: []PageFailure{},
+	}
+}
+
+// AddFailure records a page file that failed to load.
+func (r *LoadReport) AddFailure(file string, err error) {
+	r.Failures = append(r.Failures, PageFailure{File: file, Err: err})
+}
+
+// HasFailures returns true if any page failed to load.
+func (r *LoadReport) HasFailures() bool {
+	return len(r.Failures) > 0
+}
+
+// String summarizes the failures, one page per line.
+func (r *LoadReport) String() string {
+	lines := []string{fmt.Sprintf("%d pages failed to load", len(r.Failures))}
+
+	for _, failure := range r.Failures {
+		lines = append(lines, "  "+failure.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
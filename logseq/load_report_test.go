@@ -0,0 +1,28 @@
+package logseq_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/logseq"
+)
+
+func TestLoadReport_NewLoadReport(t *testing.T) {
+	report := logseq.NewLoadReport()
+
+	assert.False(t, report.HasFailures())
+	assert.Empty(t, report.Failures)
+}
+
+func TestLoadReport_AddFailure(t *testing.T) {
+	report := logseq.NewLoadReport()
+	report.AddFailure("pages/Broken.md", errors.New("broken"))
+
+	assert.True(t, report.HasFailures())
+	assert.Equal(t, "1 pages failed to load\n  pages/Broken.md: broken", report.String())
+
+	_, ok := report.Failures[0].ParseError()
+	assert.False(t, ok)
+}
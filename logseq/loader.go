@@ -3,12 +3,12 @@ package logseq
 import (
 	"bytes"
 	"fmt"
-	"net/url"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gosimple/slug"
 	"github.com/pkg/errors"
@@ -30,9 +30,14 @@ type GraphLinkResolver struct {
 }
 
 // Loader loads a Logseq graph from a directory.
+// A lenient loader skips pages that fail to load, recording them in its report.
 type Loader struct {
-	GraphDir string
-	Graph    graph.Graph
+	GraphDir  string
+	Graph     graph.Graph
+	Lenient   bool
+	Location  *time.Location // Time zone for task timestamps and clock entries
+	Report    LoadReport
+	pageFiles map[string]string // Lowercased page names to the files that define them
 }
 
 func NewLoader(graphDir string) Loader {
@@ -41,28 +46,58 @@ func NewLoader(graphDir string) Loader {
 	g.Name = filepath.Base(graphDir)
 	log.Info("Graph name: ", g.Name)
 
-	return Loader{GraphDir: graphDir, Graph: g}
+	return Loader{
+		GraphDir:  graphDir,
+		Graph:     g,
+		Location:  time.Local,
+		Report:    NewLoadReport(),
+		pageFiles: map[string]string{},
+	}
 }
 
-// LoadGraph loads a Logseq graph from a directory.
-func LoadGraph(graphDir string) (graph.Graph, error) {
-	log.Info("Loading Logseq graph from", graphDir)
+// LoadGraph loads a Logseq graph from a directory, reading task times as local time.
+// When lenient, broken pages are skipped and listed in the returned report instead of
+// stopping the load.
+func LoadGraph(graphDir string, lenient bool) (graph.Graph, LoadReport, error) {
 	loader := NewLoader(graphDir)
+	loader.Lenient = lenient
+
+	return loader.Load()
+}
+
+// Load loads the loader's graph, with its config, assets, pages, and whiteboards.
+func (loader *Loader) Load() (graph.Graph, LoadReport, error) {
+	log.Info("Loading Logseq graph from", loader.GraphDir)
+
+	config, err := LoadConfig(loader.GraphDir)
+	if err != nil {
+		return loader.Graph, loader.Report, errors.Wrap(err, "loading config")
+	}
+
+	loader.Graph.Config = config
 
 	if err := loader.loadAssets(); err != nil {
-		return loader.Graph, errors.Wrap(err, "loading assets")
+		return loader.Graph, loader.Report, errors.Wrap(err, "loading assets")
 	}
 
-	pageDirs := []string{"pages", "journals"}
+	pageDirs := []string{config.PagesDirectory, config.JournalsDirectory}
 
 	for _, pageDir := range pageDirs {
 		if err := loader.loadPagesFromDir(pageDir); err != nil {
-			return loader.Graph, errors.Wrap(err, "loading pages from "+pageDir)
+			return loader.Graph, loader.Report, errors.Wrap(err, "loading pages from "+pageDir)
 		}
 	}
 
+	if err := loader.loadWhiteboards(); err != nil {
+		return loader.Graph, loader.Report, errors.Wrap(err, "loading whiteboards")
+	}
+
+	if err := loader.applyPagesMetadata(); err != nil {
+		return loader.Graph, loader.Report, errors.Wrap(err, "loading pages metadata")
+	}
+
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM, &wikilink.Extender{}),
+		goldmark.WithExtensions(extension.GFM, &wikilink.Extender{}, &graph.MarkupExtender{}),
 	)
 
 	for _, block := range loader.Graph.Blocks {
@@ -81,94 +116,118 @@ func LoadGraph(graphDir string) (graph.Graph, error) {
 		page.TaggedLinks = loader.Graph.FindTagLinksToPage(page)
 	}
 
-	return loader.Graph, nil
+	return loader.Graph, loader.Report, nil
 }
 
+// LoadPage loads a Markdown or Org page file.
+// The page name comes from the file name unless the page sets a title property,
+// which Logseq treats as the authoritative name. Journal pages are always named
+// by their date.
 func (loader *Loader) LoadPage(pageFile string, graphPath string) (graph.Page, error) {
+	config := loader.Graph.Config
 	baseName := filepath.Base(pageFile)
-	namespace := "pages"
-	fullPageFileName := strings.ReplaceAll(baseName, "___", "/")
-	fullPageName := strings.TrimSuffix(fullPageFileName, ".md")
-	journalDateRe := regexp.MustCompile(`^\d{4}_\d{2}_\d{2}$`)
-	titleIsJournalDate := journalDateRe.MatchString(fullPageName)
-
-	if titleIsJournalDate {
-		fullPageName = strings.Replace(fullPageName, "_", "-", -1)
-		namespace = "journals"
-	} else {
-		escapedName, decodeErr := url.QueryUnescape(fullPageName)
-		if decodeErr != nil {
-			return graph.Page{}, errors.New("decoding page name: " + decodeErr.Error())
-		}
-
-		fullPageName = escapedName
-	}
+	fileNameBody := strings.TrimSuffix(baseName, filepath.Ext(baseName))
 
-	title := fullPageName
 	pathInGraph, err := filepath.Rel(graphPath, pageFile)
 	if err != nil {
 		return graph.Page{}, errors.Wrap(err, "calculating path in graph")
 	}
 
-	nameSteps := strings.Split(fullPageName, "/")
-	stepCount := len(nameSteps)
+	page := graph.Page{PathInGraph: pathInGraph}
 
-	if stepCount > 1 {
-		title = nameSteps[len(nameSteps)-1]
-		namespace = namespace + "/" + strings.Join(nameSteps[:stepCount-1], "/")
-		log.Debugf("'%s' has namespace '%s' and title '%s'", fullPageName, namespace, title)
+	journalDate, err := ParseJournalDate(fileNameBody, config.JournalFileNameFormat)
+	if err == nil {
+		page.JournalDay = journalDate.Format("2006-01-02")
+		// Journal titles may contain slashes, but they never describe a namespace.
+		setPageName(&page, FormatJournalDate(journalDate, config.JournalPageTitleFormat), "journals", false)
+	} else {
+		setPageName(&page, PageNameFromFileName(fileNameBody, config.FileNameFormat), "pages", true)
 	}
 
-	// Generate a slug for the page name
-	slugs := []string{}
+	blocks, err := loadPageBlocks(&page, pageFile, loader.Location)
+	if err != nil {
+		return graph.Page{}, err
+	}
 
-	for _, step := range nameSteps {
-		slugs = append(slugs, slug.Make(step))
+	page.Root = blocks[0]
+	page.AllBlocks = blocks
+	setFileTimestamps(&page, pageFile)
+
+	// Markdown title:: and Org #+TITLE: both end up as the root title property.
+	if titleProp, ok := page.Root.Properties.Get("title"); ok && !page.IsJournal() {
+		if title := strings.TrimSpace(titleProp.String()); title != "" {
+			setPageName(&page, title, "pages", true)
+
+			for _, block := range blocks {
+				block.PageName = page.Name
+			}
+		}
 	}
 
-	slugName := strings.Join(slugs, "/")
+	return page, nil
+}
 
-	// Process each line of fullPageName
+// loadPageBlocks reads the blocks of a Markdown or Org page file, root block first.
+// Task times are read in location.
+func loadPageBlocks(page *graph.Page, pageFile string, location *time.Location) ([]*graph.Block, error) {
 	file, err := os.Open(pageFile)
 	if err != nil {
-		return graph.Page{}, errors.New("opening page file: " + err.Error())
+		return nil, errors.New("opening page file: " + err.Error())
 	}
 	defer file.Close()
 
-	lines, err := LoadPageLines(file)
-	if err != nil {
-		return graph.Page{}, errors.New("loading page lines: " + err.Error())
-	}
+	var blocks []*graph.Block
 
-	page := graph.Page{
-		Name:        fullPageName,
-		Title:       title,
-		Namespace:   namespace,
-		PathInGraph: pathInGraph,
-		Path:        slugName,
-	}
+	if filepath.Ext(pageFile) == orgExtension {
+		blocks, err = loadOrgBlocks(page, file, location)
+		if err != nil {
+			return nil, errors.Wrap(locateParseError(err, pageFile), "loading org blocks")
+		}
+	} else {
+		lines, err := LoadPageLines(file)
+		if err != nil {
+			return nil, errors.Wrap(locateParseError(err, pageFile), "loading page lines")
+		}
 
-	blocks, err := findBlocks(&page, lines)
-	if err != nil {
-		return graph.Page{}, errors.New("finding blocks: " + err.Error())
+		blocks, err = findBlocks(page, lines, location)
+		if err != nil {
+			return nil, errors.Wrap(locateParseError(err, pageFile), "finding blocks")
+		}
 	}
 
 	if len(blocks) == 0 {
-		log.Warn("No root block found in page: ", fullPageName)
+		log.Warn("No root block found in page: ", page.Name)
 
 		blocks = []*graph.Block{graph.NewEmptyBlock()}
 	}
 
-	page.Root = blocks[0]
-	page.AllBlocks = blocks
+	return blocks, nil
+}
 
-	return page, nil
+// setPageName names a page, deriving its title, namespace and path from the full name.
+// Slashes in the name describe namespaces unless splitNamespaces is false.
+func setPageName(page *graph.Page, fullPageName string, section string, splitNamespaces bool) {
+	nameSteps := []string{fullPageName}
+	if splitNamespaces {
+		nameSteps = strings.Split(fullPageName, "/")
+	}
+
+	stepCount := len(nameSteps)
+	page.Name = fullPageName
+	page.Title = nameSteps[stepCount-1]
+	page.Namespace = section
+	page.Path = slugPath(nameSteps)
+
+	if stepCount > 1 {
+		page.Namespace = section + "/" + strings.Join(nameSteps[:stepCount-1], "/")
+		log.Debugf("'%s' has namespace '%s' and title '%s'", fullPageName, page.Namespace, page.Title)
+	}
 }
 
 func (loader *Loader) loadAssets() error {
 	assetsDir := filepath.Join(loader.GraphDir, "assets")
 	log.Info("Assets directory:", assetsDir)
-	assetFiles, err := filepath.Glob(filepath.Join(assetsDir, "*.*"))
+	assetFiles, err := loader.findFiles(assetsDir, func(string) bool { return true })
 
 	if err != nil {
 		return errors.Wrap(err, "listing asset files")
@@ -180,7 +239,7 @@ func (loader *Loader) loadAssets() error {
 			return errors.Wrap(err, "calculating relative path for asset")
 		}
 
-		asset := graph.NewAsset(relPath)
+		asset := graph.NewAsset(filepath.ToSlash(relPath))
 		err = loader.Graph.AddAsset(asset)
 
 		if err != nil {
@@ -195,49 +254,86 @@ func (loader *Loader) loadPagesFromDir(subdir string) error {
 	g := &loader.Graph
 	pagesDir := filepath.Join(g.GraphDir, subdir)
 	log.Infof("Loading pages from %s", pagesDir)
-	pageFiles, err := filepath.Glob(filepath.Join(pagesDir, "*.md"))
+	pageFiles, err := loader.findFiles(pagesDir, func(file string) bool {
+		extension := filepath.Ext(file)
+
+		return extension == ".md" || extension == orgExtension
+	})
 
 	if err != nil {
 		return errors.Wrap(err, "listing page files")
 	}
 
 	wg := new(sync.WaitGroup)
-	pageCh := make(chan graph.Page, len(pageFiles))
-	errCh := make(chan error, 1)
+	pages := make([]*graph.Page, len(pageFiles))
+	pageErrors := make([]error, len(pageFiles))
+
+	for i, pageFile := range pageFiles {
+		if g.Config.IsIgnoredFile(pageFile) {
+			log.Debug("Skipping ignored page file: ", pageFile)
 
-	for _, pageFile := range pageFiles {
-		if filepath.Base(pageFile) == "Templates.md" {
 			continue
 		}
 
 		wg.Add(1)
 
-		go func(wg *sync.WaitGroup, pageFile string) {
+		go func(wg *sync.WaitGroup, i int, pageFile string) {
 			defer wg.Done()
 
 			page, err := loader.LoadPage(pageFile, pagesDir)
 			if err != nil {
-				errCh <- errors.Wrap(err, "loading page "+pageFile)
+				pageErrors[i] = err
 
 				return
 			}
 
-			pageCh <- page
-		}(wg, pageFile)
+			pages[i] = &page
+		}(wg, i, pageFile)
 	}
 
 	wg.Wait()
-	close(errCh)
-	close(pageCh)
 
-	err = <-errCh
-	if err != nil {
-		return errors.Wrap(err, "loading pages")
+	// Report every failure, so one run shows all the pages that need fixing.
+	var firstErr error
+
+	for i, err := range pageErrors {
+		if err == nil {
+			continue
+		}
+
+		if loader.Lenient {
+			log.Warn("Skipping page that failed to load: ", err)
+			loader.Report.AddFailure(pageFiles[i], err)
+
+			continue
+		}
+
+		log.Error(err)
+
+		if firstErr == nil {
+			firstErr = errors.Wrap(err, "loading page "+pageFiles[i])
+		}
 	}
 
-	for page := range pageCh {
-		err := g.AddPage(&page)
+	if firstErr != nil {
+		return errors.Wrap(firstErr, "loading pages")
+	}
+
+	// Add pages in file order, so the same file wins every time names collide.
+	for i, page := range pages {
+		if page == nil {
+			continue
+		}
+
+		err := loader.addPage(page, pageFiles[i])
 		if err != nil {
+			if loader.Lenient {
+				log.Warn("Skipping page that could not be added: ", err)
+				loader.Report.AddFailure(pageFiles[i], err)
+
+				continue
+			}
+
 			return errors.Wrap(err, "adding page "+page.Name)
 		}
 	}
@@ -245,10 +341,90 @@ func (loader *Loader) loadPagesFromDir(subdir string) error {
 	return nil
 }
 
-func findBlocks(page *graph.Page, lines []PageLine) ([]*graph.Block, error) {
+// addPage adds a loaded page to the graph, refusing a page whose name is already
+// used by another file.
+func (loader *Loader) addPage(page *graph.Page, pageFile string) error {
+	nameKey := strings.ToLower(page.Name)
+
+	if existingFile, ok := loader.pageFiles[nameKey]; ok {
+		return PageNameCollisionError{PageName: page.Name, File: pageFile, ExistingFile: existingFile}
+	}
+
+	loader.Graph.DeriveBlockIDs(page)
+
+	if err := loader.Graph.AddPage(page); err != nil {
+		return errors.Wrap(err, "adding page to graph")
+	}
+
+	loader.pageFiles[nameKey] = pageFile
+
+	return nil
+}
+
+// findFiles recursively lists regular files under dir that pass the include check,
+// leaving out anything the graph config hides. A missing dir has no files.
+func (loader *Loader) findFiles(dir string, include func(string) bool) ([]string, error) {
+	files := []string{}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+
+			return err
+		}
+
+		if path != dir && loader.isHidden(path) {
+			log.Debug("Skipping hidden path: ", path)
+
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.Type().IsRegular() && include(path) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "walking "+dir)
+	}
+
+	return files, nil
+}
+
+// slugPath generates a slug for each step of a page name.
+func slugPath(nameSteps []string) string {
+	slugs := []string{}
+
+	for _, step := range nameSteps {
+		slugs = append(slugs, slug.Make(step))
+	}
+
+	return strings.Join(slugs, "/")
+}
+
+// isHidden returns true if the graph config hides a file from loading.
+func (loader *Loader) isHidden(file string) bool {
+	pathInGraph, err := filepath.Rel(loader.GraphDir, file)
+	if err != nil {
+		return false
+	}
+
+	return loader.Graph.Config.IsHidden(pathInGraph)
+}
+
+func findBlocks(page *graph.Page, lines []PageLine, location *time.Location) ([]*graph.Block, error) {
 	blocks := []*graph.Block{}
 	blockStack := NewBlockStack()
 	currentBlockLines := []string{}
+	currentBlockStart := PageLine{LineNumber: 1, Column: 1}
 	currentIndent := 0
 
 	for _, line := range lines {
@@ -260,10 +436,10 @@ func findBlocks(page *graph.Page, lines []PageLine) ([]*graph.Block, error) {
 
 		if strings.HasPrefix(line.Content, branchBlockOpener) {
 			// Remember the current block.
-			block, err := graph.NewBlock(page, currentBlockLines, currentIndent)
+			block, err := graph.NewBlockInLocation(page, currentBlockLines, currentIndent, location)
 
 			if err != nil {
-				return nil, errors.Wrap(err, "creating new block")
+				return nil, blockParseError(currentBlockStart, err)
 			}
 
 			blocks = append(blocks, block)
@@ -274,12 +450,13 @@ func findBlocks(page *graph.Page, lines []PageLine) ([]*graph.Block, error) {
 
 			// Reset the current block and indent
 			currentBlockLines = []string{}
+			currentBlockStart = line
 			currentIndent = line.Indent
 			line.Content = strings.TrimPrefix(line.Content, branchBlockOpener)
 		} else if strings.HasPrefix(line.Content, branchBlockContinuer) {
 			// Ensure that the current line is a continuation of a current block
 			if len(currentBlockLines) == 0 {
-				return blocks, errors.New("no block to continue: " + line.Content)
+				return blocks, NewParseError(line, "no block to continue")
 			}
 
 			line.Content = strings.TrimPrefix(line.Content, branchBlockContinuer)
@@ -289,9 +466,9 @@ func findBlocks(page *graph.Page, lines []PageLine) ([]*graph.Block, error) {
 
 		// Ensure that the current line is indented correctly
 		if line.Indent != currentIndent {
-			errMsg := fmt.Sprintf("mismatched indent: %v", line)
+			errMsg := fmt.Sprintf("mismatched indent: expected depth %d, found %d", currentIndent, line.Indent)
 
-			return blocks, errors.New(errMsg)
+			return blocks, NewParseError(line, errMsg)
 		}
 
 		currentBlockLines = append(currentBlockLines, line.Content)
@@ -299,10 +476,10 @@ func findBlocks(page *graph.Page, lines []PageLine) ([]*graph.Block, error) {
 
 	// Remember the last block.
 	if len(currentBlockLines) > 0 {
-		block, err := graph.NewBlock(page, currentBlockLines, currentIndent)
+		block, err := graph.NewBlockInLocation(page, currentBlockLines, currentIndent, location)
 
 		if err != nil {
-			return nil, errors.Wrap(err, "creating block from remaining lines")
+			return nil, blockParseError(currentBlockStart, err)
 		}
 
 		blocks = append(blocks, block)
@@ -313,3 +490,23 @@ func findBlocks(page *graph.Page, lines []PageLine) ([]*graph.Block, error) {
 
 	return blocks, nil
 }
+
+// locateParseError records the page file on a ParseError. Other errors pass through unchanged.
+func locateParseError(err error, pageFile string) error {
+	var parseErr ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = pageFile
+
+		return parseErr
+	}
+
+	return err
+}
+
+// blockParseError locates an error from creating a block at the block's first line.
+func blockParseError(blockStart PageLine, err error) ParseError {
+	parseErr := NewParseError(blockStart, "creating block")
+	parseErr.Err = err
+
+	return parseErr
+}
@@ -1,9 +1,12 @@
 package logseq_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"export-logseq/logseq"
 )
@@ -15,3 +18,197 @@ func TestLoader_NewLoader(t *testing.T) {
 	assert.NotNil(t, l)
 	assert.NotNil(t, l.Graph)
 }
+
+func writeGraphFile(t *testing.T, graphDir string, pathInGraph string, content string) {
+	t.Helper()
+
+	fullPath := filepath.Join(graphDir, filepath.FromSlash(pathInGraph))
+	require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o700))
+	require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o600))
+}
+
+func TestLoader_LoadGraph_Subfolders(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Top.md", "- top")
+	writeGraphFile(t, graphDir, "pages/projects/Nested.md", "- ![shot](../assets/storages/shots/shot.png)")
+	writeGraphFile(t, graphDir, "pages/.recycle/Deleted.md", "- deleted")
+	writeGraphFile(t, graphDir, "assets/storages/shots/shot.png", "png")
+	writeGraphFile(t, graphDir, "assets/top.png", "png")
+
+	g, _, err := logseq.LoadGraph(graphDir, false)
+
+	require.NoError(t, err)
+
+	nested, err := g.FindPage("Nested")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("projects", "Nested.md"), nested.PathInGraph)
+
+	_, err = g.FindPage("Deleted")
+	assert.Error(t, err)
+
+	asset, ok := g.FindAsset("storages/shots/shot.png")
+	assert.True(t, ok)
+	assert.Equal(t, "shot.png", asset.Name)
+
+	_, ok = g.FindAsset("top.png")
+	assert.True(t, ok)
+
+	assert.Len(t, g.AssetLinks(), 1)
+	assert.Equal(t, "storages/shots/shot.png", g.AssetLinks()[0].LinkPath)
+}
+
+func TestLoader_LoadPage_SpaceIndentedMatchesTabs(t *testing.T) {
+	pagesDir := t.TempDir()
+	writeGraphFile(t, pagesDir, "Tabs.md", "- a\n  more a\n\t- b\n\t  more b\n\t\t- c\n- d\n")
+	writeGraphFile(t, pagesDir, "Spaces.md", "- a\n  more a\n    - b\n      more b\n        - c\n- d\n")
+
+	loader := logseq.NewLoader(pagesDir)
+	tabPage, err := loader.LoadPage(filepath.Join(pagesDir, "Tabs.md"), pagesDir)
+	require.NoError(t, err)
+
+	spacePage, err := loader.LoadPage(filepath.Join(pagesDir, "Spaces.md"), pagesDir)
+	require.NoError(t, err)
+
+	require.Len(t, spacePage.AllBlocks, len(tabPage.AllBlocks))
+
+	for i, tabBlock := range tabPage.AllBlocks {
+		spaceBlock := spacePage.AllBlocks[i]
+
+		assert.Equal(t, tabBlock.Depth, spaceBlock.Depth)
+		assert.Equal(t, tabBlock.Content.Markdown, spaceBlock.Content.Markdown)
+		assert.Equal(t, len(tabBlock.Children), len(spaceBlock.Children))
+	}
+}
+
+func TestLoader_LoadGraph_ParseErrorLocation(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Good.md", "- fine")
+	writeGraphFile(t, graphDir, "pages/Broken.md", "- first\n\t- second\n\t\t\t- too deep\n\t    continued")
+
+	_, _, err := logseq.LoadGraph(graphDir, false)
+
+	require.Error(t, err)
+
+	var parseErr logseq.ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, filepath.Join(graphDir, "pages", "Broken.md"), parseErr.File)
+	assert.Equal(t, 4, parseErr.Line)
+	assert.Equal(t, 2, parseErr.Column)
+	assert.Contains(t, err.Error(), "Broken.md:4:2: mismatched indent")
+}
+
+func TestLoader_LoadGraph_Lenient(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Good.md", "- fine [[Other]]")
+	writeGraphFile(t, graphDir, "pages/Broken.md", "- first\n\t    continued")
+	writeGraphFile(t, graphDir, "journals/2024_06_03.md", "- first\n  - two\n   - three")
+
+	g, report, err := logseq.LoadGraph(graphDir, true)
+
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 2)
+
+	_, err = g.FindPage("Good")
+	assert.NoError(t, err)
+
+	_, err = g.FindPage("Broken")
+	assert.Error(t, err)
+
+	failedFiles := []string{}
+
+	for _, failure := range report.Failures {
+		failedFiles = append(failedFiles, failure.File)
+
+		_, ok := failure.ParseError()
+		assert.True(t, ok)
+	}
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join(graphDir, "pages", "Broken.md"),
+		filepath.Join(graphDir, "journals", "2024_06_03.md"),
+	}, failedFiles)
+}
+
+func TestLoader_LoadPage_TitleProperty(t *testing.T) {
+	pagesDir := t.TempDir()
+	writeGraphFile(t, pagesDir, "What%3F.md", "title:: project/What? Why: Because\npublic:: true\n\n- [[Other]]")
+
+	loader := logseq.NewLoader(pagesDir)
+	page, err := loader.LoadPage(filepath.Join(pagesDir, "What%3F.md"), pagesDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, "project/What? Why: Because", page.Name)
+	assert.Equal(t, "What? Why: Because", page.Title)
+	assert.Equal(t, "pages/project", page.Namespace)
+	assert.Equal(t, "project/what-why-because", page.Path)
+
+	for _, block := range page.AllBlocks {
+		assert.Equal(t, page.Name, block.PageName)
+	}
+}
+
+func TestLoader_LoadPage_JournalIgnoresTitleProperty(t *testing.T) {
+	journalsDir := t.TempDir()
+	writeGraphFile(t, journalsDir, "2024_06_03.md", "title:: Monday\n\n- entry")
+
+	loader := logseq.NewLoader(journalsDir)
+	page, err := loader.LoadPage(filepath.Join(journalsDir, "2024_06_03.md"), journalsDir)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Jun 3rd, 2024", page.Name)
+	assert.Equal(t, "2024-06-03", page.JournalDay)
+}
+
+func TestLoader_LoadGraph_PageNameCollision(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/project___Plan.md", "- from file name")
+	writeGraphFile(t, graphDir, "pages/Other.md", "title:: Project/Plan\n\n- from title")
+	writeGraphFile(t, graphDir, "logseq/config.edn", "{:file/name-format :triple-lowbar}")
+
+	_, _, err := logseq.LoadGraph(graphDir, false)
+
+	var collisionErr logseq.PageNameCollisionError
+
+	require.ErrorAs(t, err, &collisionErr)
+	assert.Equal(t, "project/Plan", collisionErr.PageName)
+	assert.Equal(t, filepath.Join(graphDir, "pages", "project___Plan.md"), collisionErr.File)
+	assert.Equal(t, filepath.Join(graphDir, "pages", "Other.md"), collisionErr.ExistingFile)
+
+	g, report, err := logseq.LoadGraph(graphDir, true)
+
+	require.NoError(t, err)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, filepath.Join(graphDir, "pages", "project___Plan.md"), report.Failures[0].File)
+
+	page, err := g.FindPage("project/plan")
+	require.NoError(t, err)
+	assert.Equal(t, "Other.md", page.PathInGraph)
+}
+
+func TestLoader_LoadGraph_StableBlockIDs(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Top.md", "- same\n- same\n\t- child [[Other]]\n- pinned\n  id:: 6650bf5e-0000-4000-8000-000000000001")
+
+	blockIDs := func() []string {
+		g, _, err := logseq.LoadGraph(graphDir, false)
+		require.NoError(t, err)
+
+		page, err := g.FindPage("Top")
+		require.NoError(t, err)
+
+		ids := []string{}
+		for _, block := range page.AllBlocks {
+			ids = append(ids, block.ID)
+		}
+
+		return ids
+	}
+
+	first := blockIDs()
+
+	assert.Equal(t, first, blockIDs())
+	assert.Len(t, first, 5)
+	assert.NotEqual(t, first[1], first[2], "identical siblings get distinct IDs")
+	assert.Equal(t, "6650bf5e-0000-4000-8000-000000000001", first[4])
+}
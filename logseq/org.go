@@ -0,0 +1,322 @@
+package logseq
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"export-logseq/graph"
+)
+
+const orgExtension = ".org"
+
+var (
+	orgHeadlineRe    = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgKeywordRe     = regexp.MustCompile(`^#\+([A-Za-z][\w-]*):\s*(.*)$`)
+	orgDrawerPropRe  = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+	orgBeginRe       = regexp.MustCompile(`(?i)^#\+begin_(\S+)\s*(.*)$`)
+	orgEndRe         = regexp.MustCompile(`(?i)^#\+end_(\S+)\s*$`)
+	orgBracketLinkRe = regexp.MustCompile(`\[\[([^\[\]]+)\](?:\[([^\[\]]+)\])?\]`)
+	orgAssetPathRe   = regexp.MustCompile(`^(?:file:)?(\.\./assets/.+)$`)
+	orgImageExtRe    = regexp.MustCompile(`(?i)\.(?:png|jpe?g|gif|svg|webp|bmp)$`)
+	orgURLRe         = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://`)
+)
+
+// Org emphasis markers and their Markdown equivalents. Bold goes first, so
+// italics converted to single asterisks aren't mistaken for Org bold.
+var orgEmphasis = []struct {
+	marker      byte
+	replacement string
+}{
+	{'*', "**"},
+	{'/', "*"},
+	{'+', "~~"},
+}
+
+// orgBlock collects the source of a single Org headline before it becomes a graph.Block.
+type orgBlock struct {
+	start      PageLine
+	depth      int
+	headline   string
+	properties []string
+	content    []string
+}
+
+// propertyLine formats a property in the `name:: value` form graph.NewBlock understands.
+func propertyLine(name, value string) string {
+	return strings.ToLower(name) + ":: " + value
+}
+
+// sourceLines returns property and content lines ready for graph.NewBlock.
+func (ob *orgBlock) sourceLines() []string {
+	lines := append([]string{}, ob.properties...)
+
+	if ob.depth > 0 {
+		lines = append(lines, ob.headline)
+	}
+
+	return append(lines, dedentLines(ob.content)...)
+}
+
+// loadOrgBlocks reads an Org page and builds its block tree.
+// Headline depth becomes block depth, with everything before the first headline in the root block.
+func loadOrgBlocks(page *graph.Page, r io.Reader, location *time.Location) ([]*graph.Block, error) {
+	orgBlocks := []*orgBlock{{start: PageLine{LineNumber: 1, Column: 1}, depth: 0}}
+	current := orgBlocks[0]
+	inDrawer := false
+	inSource := false
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNumber++
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		if inSource {
+			if orgEndRe.MatchString(trimmed) {
+				current.content = append(current.content, indent+"```")
+				inSource = false
+
+				continue
+			}
+
+			current.content = append(current.content, line)
+
+			continue
+		}
+
+		if inDrawer {
+			if strings.EqualFold(trimmed, ":END:") {
+				inDrawer = false
+
+				continue
+			}
+
+			if match := orgDrawerPropRe.FindStringSubmatch(trimmed); match != nil {
+				current.properties = append(current.properties, propertyLine(match[1], match[2]))
+			}
+
+			continue
+		}
+
+		if match := orgHeadlineRe.FindStringSubmatch(line); match != nil {
+			current = &orgBlock{
+				start:    PageLine{Content: line, LineNumber: lineNumber, Column: 1},
+				depth:    len(match[1]),
+				headline: convertOrgInline(match[2]),
+			}
+			orgBlocks = append(orgBlocks, current)
+
+			continue
+		}
+
+		// Property drawers belong to the headline (or page) directly above them.
+		if strings.EqualFold(trimmed, ":PROPERTIES:") && len(current.content) == 0 {
+			inDrawer = true
+
+			continue
+		}
+
+		if match := orgBeginRe.FindStringSubmatch(trimmed); match != nil {
+			kind := strings.ToUpper(match[1])
+
+			if kind == "SRC" || kind == "EXAMPLE" {
+				language := ""
+				if fields := strings.Fields(match[2]); len(fields) > 0 {
+					language = fields[0]
+				}
+
+				current.content = append(current.content, indent+"```"+language)
+				inSource = true
+
+				continue
+			}
+
+			current.content = append(current.content, indent+"#+BEGIN_"+kind)
+
+			continue
+		}
+
+		if match := orgEndRe.FindStringSubmatch(trimmed); match != nil {
+			current.content = append(current.content, indent+"#+END_"+strings.ToUpper(match[1]))
+
+			continue
+		}
+
+		if match := orgKeywordRe.FindStringSubmatch(trimmed); match != nil && current.depth == 0 {
+			current.properties = append(current.properties, propertyLine(match[1], match[2]))
+
+			continue
+		}
+
+		// Org comment lines
+		if trimmed == "#" || strings.HasPrefix(trimmed, "# ") {
+			continue
+		}
+
+		current.content = append(current.content, convertOrgInline(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "scanning org page")
+	}
+
+	blocks := []*graph.Block{}
+	blockStack := NewBlockStack()
+
+	for _, ob := range orgBlocks {
+		block, err := graph.NewBlockInLocation(page, ob.sourceLines(), ob.depth, location)
+		if err != nil {
+			return nil, blockParseError(ob.start, err)
+		}
+
+		blocks = append(blocks, block)
+		blockStack = PlaceBlock(block, blockStack)
+	}
+
+	log.Debug("Org blocks: ", blocks)
+
+	return blocks, nil
+}
+
+// dedentLines removes indentation shared by all non-blank lines.
+func dedentLines(lines []string) []string {
+	indent := -1
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || lineIndent < indent {
+			indent = lineIndent
+		}
+	}
+
+	dedented := []string{}
+
+	for _, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+
+		dedented = append(dedented, line)
+	}
+
+	// Trailing blank lines only separate headlines in Org.
+	for len(dedented) > 0 && strings.TrimSpace(dedented[len(dedented)-1]) == "" {
+		dedented = dedented[:len(dedented)-1]
+	}
+
+	return dedented
+}
+
+// convertOrgInline rewrites Org inline markup as the Markdown the rest of the loader expects.
+// Labelled page links keep their Org form, since BlockContent recognizes them directly.
+func convertOrgInline(line string) string {
+	line = orgBracketLinkRe.ReplaceAllStringFunc(line, convertOrgLink)
+	segments := strings.Split(convertOrgVerbatim(line), "`")
+
+	// Even segments are outside inline code.
+	for i := 0; i < len(segments); i += 2 {
+		for _, emphasis := range orgEmphasis {
+			segments[i] = convertOrgEmphasis(segments[i], emphasis.marker, emphasis.replacement)
+		}
+	}
+
+	return strings.Join(segments, "`")
+}
+
+func convertOrgLink(raw string) string {
+	match := orgBracketLinkRe.FindStringSubmatch(raw)
+	target, label := match[1], match[2]
+
+	if assetMatch := orgAssetPathRe.FindStringSubmatch(target); assetMatch != nil {
+		assetPath := assetMatch[1]
+
+		if label == "" {
+			label = filepath.Base(assetPath)
+		}
+
+		if orgImageExtRe.MatchString(assetPath) {
+			return "![" + label + "](" + assetPath + ")"
+		}
+
+		return "[" + label + "](" + assetPath + ")"
+	}
+
+	if orgURLRe.MatchString(target) {
+		if label == "" {
+			return "<" + target + ">"
+		}
+
+		return "[" + label + "](" + target + ")"
+	}
+
+	return raw
+}
+
+func convertOrgVerbatim(line string) string {
+	for _, marker := range []byte{'=', '~'} {
+		line = convertOrgEmphasis(line, marker, "`")
+	}
+
+	return line
+}
+
+// convertOrgEmphasis replaces one kind of Org emphasis, following Org's rules
+// for which characters may surround the markers.
+func convertOrgEmphasis(text string, marker byte, replacement string) string {
+	const preChars = " \t('\"{"
+
+	const postChars = " \t-.,:!?;'\")}["
+
+	var sb strings.Builder
+
+	i := 0
+	for i < len(text) {
+		isOpening := text[i] == marker &&
+			(i == 0 || strings.IndexByte(preChars, text[i-1]) >= 0) &&
+			i+1 < len(text) && text[i+1] != ' ' && text[i+1] != marker
+
+		if !isOpening {
+			sb.WriteByte(text[i])
+			i++
+
+			continue
+		}
+
+		end := -1
+
+		for j := i + 2; j < len(text); j++ {
+			if text[j] == marker && text[j-1] != ' ' &&
+				(j+1 == len(text) || strings.IndexByte(postChars, text[j+1]) >= 0) {
+				end = j
+
+				break
+			}
+		}
+
+		if end < 0 {
+			sb.WriteByte(text[i])
+			i++
+
+			continue
+		}
+
+		sb.WriteString(replacement + text[i+1:end] + replacement)
+		i = end + 1
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package logseq_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/logseq"
+)
+
+const orgPage = `#+TITLE: Reading List
+#+tags: books
+:PROPERTIES:
+:public: true
+:END:
+
+* Fiction
+:PROPERTIES:
+:id: 6650d3c6-0000-4000-8000-000000000001
+:END:
+  Some *bold* and /italic/ text with =code=.
+** TODO Read [[Dune][the Dune novel]]
+** Cover [[file:../assets/dune.png]]
+* Reference
+  # a comment line
+  See [[https://example.com][the site]] and [[Other Page]].
+* Code
+  #+begin_src go
+  fmt.Println("*not bold*")
+  #+end_src
+`
+
+func writeOrgPage(t *testing.T) string {
+	t.Helper()
+
+	pagesDir := t.TempDir()
+	pageFile := filepath.Join(pagesDir, "Reading List.org")
+	require.NoError(t, os.WriteFile(pageFile, []byte(orgPage), 0o600))
+
+	return pageFile
+}
+
+func TestOrg_LoadPage(t *testing.T) {
+	pageFile := writeOrgPage(t)
+	loader := logseq.NewLoader(filepath.Dir(pageFile))
+	page, err := loader.LoadPage(pageFile, filepath.Dir(pageFile))
+
+	require.NoError(t, err)
+	assert.Equal(t, "Reading List", page.Name)
+	assert.Equal(t, "Reading List", page.Title)
+	assert.True(t, page.IsPublic())
+	assert.Equal(t, []string{"books"}, page.Tags())
+	require.Len(t, page.Root.Children, 3)
+
+	fiction := page.Root.Children[0]
+	assert.Equal(t, 1, fiction.Depth)
+	assert.Equal(t, "6650d3c6-0000-4000-8000-000000000001", fiction.ID)
+	assert.Equal(t, "Fiction\nSome **bold** and *italic* text with `code`.", fiction.Content.Markdown)
+	require.Len(t, fiction.Children, 2)
+
+	task := fiction.Children[0]
+	assert.Equal(t, 2, task.Depth)
+	assert.True(t, task.IsTask())
+
+	link, ok := task.Content.FindLink("Dune")
+	assert.True(t, ok)
+	assert.Equal(t, "the Dune novel", link.Label)
+
+	cover := fiction.Children[1]
+	_, ok = cover.Content.FindLink("dune.png")
+	assert.True(t, ok)
+
+	reference := page.Root.Children[1]
+	assert.NotContains(t, reference.Content.Markdown, "a comment line")
+	assert.Contains(t, reference.Content.Markdown, "[the site](https://example.com)")
+
+	_, ok = reference.Content.FindLink("Other Page")
+	assert.True(t, ok)
+
+	code := page.Root.Children[2]
+	assert.Equal(t, "Code\n```go\nfmt.Println(\"*not bold*\")\n```", code.Content.Markdown)
+}
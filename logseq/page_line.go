@@ -11,11 +11,13 @@ import (
 )
 
 type PageLine struct {
-	Content string
-	Indent  int
+	Content    string
+	Indent     int
+	LineNumber int // 1-based line in the page file, when loaded from one
+	Column     int // 1-based column where Content starts in the page file
 }
 
-// NewPageLine creates a new PageLine from a string.
+// NewPageLine creates a new PageLine from a tab-indented string.
 func NewPageLine(line string) PageLine {
 	fullLength := utf8.RuneCountInString(line)
 	lineContent := strings.TrimLeft(line, "\t")
@@ -27,23 +29,158 @@ func NewPageLine(line string) PageLine {
 	}
 }
 
+// IndentUnit describes how a page file indents nested blocks.
+// Tabs always count as one level. Spaces is the number of spaces per level,
+// or zero when the page only uses tabs.
+type IndentUnit struct {
+	Spaces int
+}
+
+// leadingWhitespace counts the tabs and spaces before a line's content.
+func leadingWhitespace(line string) (int, int, string) {
+	tabs, spaces := 0, 0
+
+	for i, r := range line {
+		switch r {
+		case '\t':
+			tabs++
+		case ' ':
+			spaces++
+		default:
+			return tabs, spaces, line[i:]
+		}
+	}
+
+	return tabs, spaces, ""
+}
+
+// isBulletLine returns true if the line content opens a block.
+func isBulletLine(content string) bool {
+	return content == "-" || strings.HasPrefix(content, branchBlockOpener)
+}
+
+// DetectIndentUnit works out the indentation used for block bullets in a page.
+// The smallest space indent on a bullet is the unit, and every other bullet must
+// use a multiple of it. Lines inside fenced code are ignored.
+func DetectIndentUnit(lines []string) (IndentUnit, error) {
+	unit := IndentUnit{}
+	inFence := false
+
+	type spacedBullet struct {
+		line   PageLine
+		spaces int
+	}
+
+	spacedBullets := []spacedBullet{}
+
+	for i, line := range lines {
+		_, spaces, content := leadingWhitespace(line)
+
+		if strings.HasPrefix(strings.TrimPrefix(content, branchBlockOpener), "```") {
+			inFence = !inFence
+
+			continue
+		}
+
+		if inFence || !isBulletLine(content) || spaces == 0 {
+			continue
+		}
+
+		bulletLine := PageLine{Content: content, LineNumber: i + 1, Column: columnOf(line, content)}
+		spacedBullets = append(spacedBullets, spacedBullet{bulletLine, spaces})
+
+		if unit.Spaces == 0 || spaces < unit.Spaces {
+			unit.Spaces = spaces
+		}
+	}
+
+	for _, bullet := range spacedBullets {
+		if bullet.spaces%unit.Spaces != 0 {
+			message := fmt.Sprintf("ambiguous indentation: %d spaces is not a multiple of %d", bullet.spaces, unit.Spaces)
+
+			return unit, NewParseError(bullet.line, message)
+		}
+	}
+
+	return unit, nil
+}
+
+// PageLine creates a PageLine using this indent unit.
+// Lines that don't open a block are read in the context of the current block depth,
+// so continuation lines keep the two-space continuer that findBlocks expects.
+func (u IndentUnit) PageLine(line string, blockIndent int) PageLine {
+	if u.Spaces == 0 {
+		return NewPageLine(line)
+	}
+
+	tabs, spaces, content := leadingWhitespace(line)
+
+	if isBulletLine(content) {
+		return PageLine{Content: content, Indent: tabs + spaces/u.Spaces}
+	}
+
+	if tabs <= blockIndent {
+		contextSpaces := (blockIndent - tabs) * u.Spaces
+
+		if spaces >= contextSpaces {
+			return PageLine{
+				Content: strings.Repeat(" ", spaces-contextSpaces) + content,
+				Indent:  blockIndent,
+			}
+		}
+	}
+
+	indent := tabs + spaces/u.Spaces
+
+	return PageLine{
+		Content: strings.Repeat(" ", spaces%u.Spaces) + content,
+		Indent:  indent,
+	}
+}
+
+// LoadPageLines reads page lines, detecting whether the page is indented with tabs or spaces.
 func LoadPageLines(r io.Reader) ([]PageLine, error) {
-	var lines []PageLine
+	var rawLines []string
 
 	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
-		pageLine := NewPageLine(scanner.Text())
-		lines = append(lines, pageLine)
+		rawLines = append(rawLines, scanner.Text())
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, errors.Wrap(err, "scanning page lines")
 	}
 
+	unit, err := DetectIndentUnit(rawLines)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []PageLine
+
+	blockIndent := 0
+
+	for i, rawLine := range rawLines {
+		pageLine := unit.PageLine(rawLine, blockIndent)
+		pageLine.LineNumber = i + 1
+		pageLine.Column = columnOf(rawLine, pageLine.Content)
+
+		if isBulletLine(pageLine.Content) {
+			blockIndent = pageLine.Indent
+		}
+
+		lines = append(lines, pageLine)
+	}
+
 	return lines, nil
 }
 
+// columnOf returns the 1-based column where content starts at the end of a raw line.
+func columnOf(rawLine string, content string) int {
+	return utf8.RuneCountInString(rawLine) - utf8.RuneCountInString(content) + 1
+}
+
 func (pl PageLine) String() string {
 	return fmt.Sprintf("<PageLine: Depth=%d; Content=%s>", pl.Indent, pl.Content)
 }
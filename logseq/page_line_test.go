@@ -2,9 +2,11 @@ package logseq_test
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"export-logseq/logseq"
 )
@@ -38,11 +40,11 @@ func TestPageLine_LoadPageLines(t *testing.T) {
 	}
 
 	expected := []logseq.PageLine{
-		{Content: "line 1", Indent: 0},
-		{Content: "  line 2", Indent: 0},
-		{Content: "line 3", Indent: 1},
-		{Content: "  line 4", Indent: 1},
-		{Content: "line 5", Indent: 0},
+		{Content: "line 1", Indent: 0, LineNumber: 1, Column: 1},
+		{Content: "  line 2", Indent: 0, LineNumber: 2, Column: 1},
+		{Content: "line 3", Indent: 1, LineNumber: 3, Column: 2},
+		{Content: "  line 4", Indent: 1, LineNumber: 4, Column: 2},
+		{Content: "line 5", Indent: 0, LineNumber: 5, Column: 1},
 	}
 
 	var buffer bytes.Buffer
@@ -58,3 +60,69 @@ func TestPageLine_LoadPageLines(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expected, pl)
 }
+
+func TestPageLine_DetectIndentUnit(t *testing.T) {
+	detectTests := []struct {
+		name  string
+		lines []string
+		want  int
+	}{
+		{"tabs", []string{"- a", "\t- b", "\t\t- c"}, 0},
+		{"two spaces", []string{"- a", "  - b", "    - c"}, 2},
+		{"four spaces", []string{"- a", "    - b", "        - c"}, 4},
+		{"mixed", []string{"- a", "\t- b", "\t  - c", "    - d"}, 2},
+		{"continuations", []string{"- a", "  text", "  - b", "    text"}, 2},
+		{"fenced code", []string{"- ```yaml", "   - odd", "  ```", "  - b"}, 2},
+	}
+
+	for _, tt := range detectTests {
+		unit, err := logseq.DetectIndentUnit(tt.lines)
+
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, unit.Spaces, tt.name)
+	}
+}
+
+func TestPageLine_DetectIndentUnit_Ambiguous(t *testing.T) {
+	lines := []string{"- a", "  - b", "   - c"}
+	_, err := logseq.DetectIndentUnit(lines)
+
+	require.Error(t, err)
+
+	var parseErr logseq.ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 3, parseErr.Line)
+	assert.Equal(t, 4, parseErr.Column)
+	assert.Equal(t, "- c", parseErr.Snippet)
+}
+
+func TestPageLine_LoadPageLines_Spaces(t *testing.T) {
+	pageLines := []string{
+		"title:: spaced",
+		"",
+		"- line 1",
+		"  line 2",
+		"  - line 3",
+		"    line 4",
+		"      line 5",
+		"- line 6",
+	}
+
+	expected := []logseq.PageLine{
+		{Content: "title:: spaced", Indent: 0, LineNumber: 1, Column: 1},
+		{Content: "", Indent: 0, LineNumber: 2, Column: 1},
+		{Content: "- line 1", Indent: 0, LineNumber: 3, Column: 1},
+		{Content: "  line 2", Indent: 0, LineNumber: 4, Column: 1},
+		{Content: "- line 3", Indent: 1, LineNumber: 5, Column: 3},
+		{Content: "  line 4", Indent: 1, LineNumber: 6, Column: 3},
+		{Content: "    line 5", Indent: 1, LineNumber: 7, Column: 3},
+		{Content: "- line 6", Indent: 0, LineNumber: 8, Column: 1},
+	}
+
+	reader := strings.NewReader(strings.Join(pageLines, "\n"))
+	pl, err := logseq.LoadPageLines(reader)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pl)
+}
@@ -0,0 +1,167 @@
+package logseq
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"olympos.io/encoding/edn"
+
+	"export-logseq/graph"
+)
+
+const pagesMetadataPath = "logseq/pages-metadata.edn"
+
+// PageMetadata holds the timestamps Logseq keeps for a page.
+type PageMetadata struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// pageMetadataEDN mirrors an entry in logseq/pages-metadata.edn.
+// Timestamps are milliseconds since the Unix epoch.
+type pageMetadataEDN struct {
+	Name      string `edn:"block/name"`
+	CreatedAt int64  `edn:"block/created-at"`
+	UpdatedAt int64  `edn:"block/updated-at"`
+}
+
+// LoadPagesMetadata reads logseq/pages-metadata.edn from a graph directory.
+// A graph without a metadata file has no metadata.
+func LoadPagesMetadata(graphDir string) (map[string]PageMetadata, error) {
+	metadataFile := filepath.Join(graphDir, pagesMetadataPath)
+	file, err := os.Open(metadataFile)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Debug("No pages metadata file found: ", metadataFile)
+
+			return map[string]PageMetadata{}, nil
+		}
+
+		return nil, errors.Wrap(err, "opening pages metadata file")
+	}
+
+	defer file.Close()
+
+	return ReadPagesMetadata(file)
+}
+
+// ReadPagesMetadata parses pages metadata EDN into timestamps keyed by lowercased page name.
+func ReadPagesMetadata(r io.Reader) (map[string]PageMetadata, error) {
+	raw := []pageMetadataEDN{}
+
+	if err := edn.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "decoding pages metadata EDN")
+	}
+
+	metadata := map[string]PageMetadata{}
+
+	for _, entry := range raw {
+		if entry.Name == "" {
+			continue
+		}
+
+		metadata[strings.ToLower(entry.Name)] = PageMetadata{
+			CreatedAt: millisToTime(entry.CreatedAt),
+			UpdatedAt: millisToTime(entry.UpdatedAt),
+		}
+	}
+
+	return metadata, nil
+}
+
+// applyPagesMetadata sets page timestamps from logseq/pages-metadata.edn,
+// which takes precedence over what the page files say.
+func (loader *Loader) applyPagesMetadata() error {
+	metadata, err := LoadPagesMetadata(loader.GraphDir)
+	if err != nil {
+		return err
+	}
+
+	for nameKey, pageMetadata := range metadata {
+		page, ok := loader.Graph.Pages[nameKey]
+		if !ok {
+			continue
+		}
+
+		page.SetTimestamps(pageMetadata.CreatedAt, pageMetadata.UpdatedAt)
+	}
+
+	return nil
+}
+
+// setFileTimestamps sets page timestamps from block created-at and updated-at properties,
+// falling back to the page file's modification time.
+func setFileTimestamps(page *graph.Page, pageFile string) {
+	created, updated := blockTimestamps(page.AllBlocks)
+
+	if created.IsZero() || updated.IsZero() {
+		info, err := os.Stat(pageFile)
+		if err != nil {
+			log.Warn("Unable to read page file modification time: ", err)
+		} else {
+			if created.IsZero() {
+				created = info.ModTime()
+			}
+
+			if updated.IsZero() {
+				updated = info.ModTime()
+			}
+		}
+	}
+
+	page.SetTimestamps(created, updated)
+}
+
+// blockTimestamps finds the earliest created-at and latest updated-at block properties.
+func blockTimestamps(blocks []*graph.Block) (time.Time, time.Time) {
+	var created, updated time.Time
+
+	for _, block := range blocks {
+		if blockCreated, ok := timestampProperty(block, "created-at"); ok {
+			if created.IsZero() || blockCreated.Before(created) {
+				created = blockCreated
+			}
+		}
+
+		if blockUpdated, ok := timestampProperty(block, "updated-at"); ok {
+			if blockUpdated.After(updated) {
+				updated = blockUpdated
+			}
+		}
+	}
+
+	return created, updated
+}
+
+// timestampProperty reads a block property holding milliseconds since the Unix epoch.
+func timestampProperty(block *graph.Block, name string) (time.Time, bool) {
+	prop, ok := block.Properties.Get(name)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	millis, err := strconv.ParseInt(strings.TrimSpace(prop.Value), 10, 64)
+	if err != nil {
+		log.Warnf("Ignoring invalid %s timestamp in block %s: %s", name, block.ID, prop.Value)
+
+		return time.Time{}, false
+	}
+
+	return millisToTime(millis), true
+}
+
+// millisToTime converts milliseconds since the Unix epoch to a UTC time, keeping zero as zero.
+func millisToTime(millis int64) time.Time {
+	if millis == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(millis).UTC()
+}
@@ -0,0 +1,64 @@
+package logseq_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/logseq"
+)
+
+func TestPageMetadata_ReadPagesMetadata(t *testing.T) {
+	metadataEDN := `[{:block/name "reading list"
+  :block/created-at 1700000000000
+  :block/updated-at 1710000000000}
+ {:block/name "draft"
+  :block/created-at 1700000000000}
+ {:block/created-at 1700000000000}]`
+
+	metadata, err := logseq.ReadPagesMetadata(strings.NewReader(metadataEDN))
+
+	require.NoError(t, err)
+	assert.Len(t, metadata, 2)
+	assert.Equal(t, time.UnixMilli(1700000000000).UTC(), metadata["reading list"].CreatedAt)
+	assert.Equal(t, time.UnixMilli(1710000000000).UTC(), metadata["reading list"].UpdatedAt)
+	assert.True(t, metadata["draft"].UpdatedAt.IsZero())
+}
+
+func TestLoader_LoadGraph_PageTimestamps(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Blocks.md",
+		"- first\n  created-at:: 1700000000000\n  updated-at:: 1700000000000\n"+
+			"- second\n  created-at:: 1690000000000\n  updated-at:: 1710000000000\n")
+	writeGraphFile(t, graphDir, "pages/Metadata.md", "- first\n  created-at:: 1690000000000\n")
+	writeGraphFile(t, graphDir, "pages/Plain.md", "- no timestamps")
+	writeGraphFile(t, graphDir, "logseq/pages-metadata.edn",
+		`[{:block/name "metadata" :block/created-at 1600000000000 :block/updated-at 1650000000000}]`)
+
+	fileTime := time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(graphDir, "pages", "Plain.md"), fileTime, fileTime))
+
+	g, _, err := logseq.LoadGraph(graphDir, false)
+
+	require.NoError(t, err)
+
+	blocksPage, err := g.FindPage("Blocks")
+	require.NoError(t, err)
+	assert.Equal(t, time.UnixMilli(1690000000000).UTC(), *blocksPage.CreatedAt)
+	assert.Equal(t, time.UnixMilli(1710000000000).UTC(), *blocksPage.UpdatedAt)
+
+	metadataPage, err := g.FindPage("Metadata")
+	require.NoError(t, err)
+	assert.Equal(t, time.UnixMilli(1600000000000).UTC(), *metadataPage.CreatedAt)
+	assert.Equal(t, time.UnixMilli(1650000000000).UTC(), *metadataPage.UpdatedAt)
+
+	plainPage, err := g.FindPage("Plain")
+	require.NoError(t, err)
+	assert.True(t, fileTime.Equal(*plainPage.CreatedAt))
+	assert.True(t, fileTime.Equal(*plainPage.UpdatedAt))
+}
@@ -0,0 +1,53 @@
+package logseq
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Longest snippet of source text included in a ParseError.
+const maxSnippetLength = 60
+
+// ParseError locates a problem found while parsing a page file.
+type ParseError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Snippet string `json:"snippet"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+// NewParseError creates a ParseError for a page line, trimming long snippets.
+func NewParseError(line PageLine, message string) ParseError {
+	return ParseError{
+		Line:    line.LineNumber,
+		Column:  line.Column,
+		Snippet: snippet(line.Content),
+		Message: message,
+	}
+}
+
+func (e ParseError) Error() string {
+	location := fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Column)
+	message := e.Message
+
+	if e.Err != nil {
+		message = message + ": " + e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s: %q", location, message, e.Snippet)
+}
+
+// Unwrap returns the error that caused the parse error, if any.
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+func snippet(text string) string {
+	if utf8.RuneCountInString(text) <= maxSnippetLength {
+		return text
+	}
+
+	return string([]rune(text)[:maxSnippetLength]) + "…"
+}
@@ -0,0 +1,25 @@
+package logseq_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"export-logseq/logseq"
+)
+
+func TestParseError_Error(t *testing.T) {
+	line := logseq.PageLine{Content: "- broken", Indent: 2, LineNumber: 12, Column: 3}
+	parseErr := logseq.NewParseError(line, "mismatched indent")
+	parseErr.File = "pages/Broken.md"
+
+	assert.Equal(t, `pages/Broken.md:12:3: mismatched indent: "- broken"`, parseErr.Error())
+}
+
+func TestParseError_Snippet(t *testing.T) {
+	line := logseq.PageLine{Content: strings.Repeat("x", 100), LineNumber: 1, Column: 1}
+	parseErr := logseq.NewParseError(line, "too long")
+
+	assert.Equal(t, strings.Repeat("x", 60)+"…", parseErr.Snippet)
+}
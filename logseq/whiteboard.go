@@ -0,0 +1,321 @@
+package logseq
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"olympos.io/encoding/edn"
+
+	"export-logseq/graph"
+)
+
+const whiteboardExtension = ".edn"
+
+// tldrawShape mirrors the :logseq.tldraw.shape property of a whiteboard block.
+type tldrawShape struct {
+	ID        string      `edn:"id"`
+	Type      string      `edn:"type"`
+	Point     []float64   `edn:"point"`
+	Size      []float64   `edn:"size"`
+	Text      string      `edn:"text"`
+	Label     string      `edn:"label"`
+	Stroke    string      `edn:"stroke"`
+	Fill      string      `edn:"fill"`
+	PageID    string      `edn:"pageId"`
+	BlockType string      `edn:"blockType"`
+	Points    [][]float64 `edn:"points"`
+	Handles   map[edn.Keyword]struct {
+		Point []float64 `edn:"point"`
+	} `edn:"handles"`
+}
+
+// whiteboardBlockEDN mirrors a block entry in a whiteboard file.
+type whiteboardBlockEDN struct {
+	UUID       edn.Tag `edn:"block/uuid"`
+	Content    string  `edn:"block/content"`
+	Properties struct {
+		Shape *tldrawShape `edn:"logseq.tldraw.shape"`
+	} `edn:"block/properties"`
+}
+
+// whiteboardPageEDN mirrors the page entry in a whiteboard file.
+type whiteboardPageEDN struct {
+	OriginalName string                      `edn:"block/original-name"`
+	Properties   map[edn.Keyword]interface{} `edn:"block/properties"`
+}
+
+// whiteboardEDN mirrors a whiteboard file.
+type whiteboardEDN struct {
+	Blocks []whiteboardBlockEDN `edn:"blocks"`
+	Pages  []whiteboardPageEDN  `edn:"pages"`
+}
+
+// Whiteboard page properties that only matter to tldraw.
+var tldrawPageProperties = map[edn.Keyword]bool{
+	"ls-type":             true,
+	"logseq.tldraw.page":  true,
+	"logseq.tldraw.shape": true,
+}
+
+// ednPropertyValue writes a page property value from a whiteboard file the way Logseq
+// writes it in Markdown. Sets hold page references, and vectors and lists become comma
+// separated values. Maps have no property syntax, so they're left out.
+func ednPropertyValue(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case map[interface{}]bool:
+		refs := []string{}
+
+		for item := range value {
+			if name, ok := item.(string); ok {
+				refs = append(refs, "[["+name+"]]")
+			} else {
+				refs = append(refs, ednScalar(item))
+			}
+		}
+
+		sort.Strings(refs)
+
+		return strings.Join(refs, ", "), true
+	case []interface{}:
+		items := []string{}
+
+		for _, item := range value {
+			items = append(items, ednScalar(item))
+		}
+
+		return strings.Join(items, ", "), true
+	case map[interface{}]interface{}:
+		return "", false
+	default:
+		return ednScalar(value), true
+	}
+}
+
+// ednScalar writes a single EDN value, with keywords losing their colon.
+func ednScalar(value interface{}) string {
+	if keyword, ok := value.(edn.Keyword); ok {
+		return string(keyword)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func (loader *Loader) loadWhiteboards() error {
+	whiteboardsDir := filepath.Join(loader.GraphDir, loader.Graph.Config.WhiteboardsDirectory)
+	log.Infof("Loading whiteboards from %s", whiteboardsDir)
+
+	whiteboardFiles, err := loader.findFiles(whiteboardsDir, func(file string) bool {
+		return filepath.Ext(file) == whiteboardExtension
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "listing whiteboard files")
+	}
+
+	for _, whiteboardFile := range whiteboardFiles {
+		page, err := loader.LoadWhiteboard(whiteboardFile, whiteboardsDir)
+		if err == nil {
+			err = loader.addPage(&page, whiteboardFile)
+		}
+
+		if err != nil {
+			if loader.Lenient {
+				log.Warn("Skipping whiteboard that failed to load: ", err)
+				loader.Report.AddFailure(whiteboardFile, err)
+
+				continue
+			}
+
+			return errors.Wrap(err, "loading whiteboard "+whiteboardFile)
+		}
+	}
+
+	return nil
+}
+
+// LoadWhiteboard loads a whiteboard file as a page.
+// Shapes with text and portals become child blocks of the page root, so they contribute links.
+func (loader *Loader) LoadWhiteboard(whiteboardFile string, graphPath string) (graph.Page, error) {
+	file, err := os.Open(whiteboardFile)
+	if err != nil {
+		return graph.Page{}, errors.Wrap(err, "opening whiteboard file")
+	}
+
+	defer file.Close()
+
+	var raw whiteboardEDN
+	if err := edn.NewDecoder(file).Decode(&raw); err != nil {
+		return graph.Page{}, errors.Wrap(err, "decoding whiteboard EDN")
+	}
+
+	pathInGraph, err := filepath.Rel(graphPath, whiteboardFile)
+	if err != nil {
+		return graph.Page{}, errors.Wrap(err, "calculating path in graph")
+	}
+
+	baseName := filepath.Base(whiteboardFile)
+	fullPageName := PageNameFromFileName(
+		strings.TrimSuffix(baseName, whiteboardExtension), loader.Graph.Config.FileNameFormat,
+	)
+	propertyLines := []string{}
+
+	if len(raw.Pages) > 0 {
+		pageEDN := raw.Pages[0]
+
+		if pageEDN.OriginalName != "" {
+			fullPageName = pageEDN.OriginalName
+		}
+
+		// Properties come from a map, so sort them to keep the root block the same between loads.
+		names := []string{}
+		for name := range pageEDN.Properties {
+			names = append(names, string(name))
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			if tldrawPageProperties[edn.Keyword(name)] {
+				continue
+			}
+
+			value, ok := ednPropertyValue(pageEDN.Properties[edn.Keyword(name)])
+			if !ok {
+				log.Debugf("Skipping whiteboard property %s with a map value", name)
+
+				continue
+			}
+
+			propertyLines = append(propertyLines, propertyLine(name, value))
+		}
+	}
+
+	page := graph.Page{
+		PathInGraph: pathInGraph,
+		Whiteboard:  graph.NewWhiteboard(),
+	}
+	setPageName(&page, fullPageName, "whiteboards", true)
+
+	root, err := graph.NewBlockInLocation(&page, propertyLines, 0, loader.Location)
+	if err != nil {
+		return graph.Page{}, errors.Wrap(err, "creating whiteboard root block")
+	}
+
+	for _, blockEDN := range raw.Blocks {
+		blockID := ""
+		if blockEDN.UUID.Value != nil {
+			blockID = fmt.Sprint(blockEDN.UUID.Value)
+		}
+
+		content := blockEDN.Content
+		isPortal := false
+
+		if shapeEDN := blockEDN.Properties.Shape; shapeEDN != nil {
+			shape := newWhiteboardShape(*shapeEDN, blockID)
+			page.Whiteboard.Shapes = append(page.Whiteboard.Shapes, shape)
+
+			if content == "" {
+				content = shapeContent(shape)
+				isPortal = shape.IsPortal()
+			}
+		}
+
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		sourceLines := strings.Split(content, "\n")
+		if blockID != "" {
+			sourceLines = append([]string{propertyLine("id", blockID)}, sourceLines...)
+		}
+
+		block, err := graph.NewBlockInLocation(&page, sourceLines, 1, loader.Location)
+		if err != nil {
+			return graph.Page{}, errors.Wrap(err, "creating whiteboard block "+blockID)
+		}
+
+		if isPortal {
+			markPortalEmbeds(block)
+		}
+
+		root.AddChild(block)
+	}
+
+	page.SetRoot(root)
+	setFileTimestamps(&page, whiteboardFile)
+
+	return page, nil
+}
+
+func newWhiteboardShape(shapeEDN tldrawShape, blockID string) graph.WhiteboardShape {
+	shape := graph.WhiteboardShape{
+		ID:      shapeEDN.ID,
+		BlockID: blockID,
+		Type:    shapeEDN.Type,
+		Text:    shapeEDN.Text,
+		Stroke:  shapeEDN.Stroke,
+		Fill:    shapeEDN.Fill,
+		Points:  []graph.Point{},
+	}
+
+	if shape.Text == "" {
+		shape.Text = shapeEDN.Label
+	}
+
+	if len(shapeEDN.Point) >= 2 {
+		shape.X, shape.Y = shapeEDN.Point[0], shapeEDN.Point[1]
+	}
+
+	if len(shapeEDN.Size) >= 2 {
+		shape.Width, shape.Height = shapeEDN.Size[0], shapeEDN.Size[1]
+	}
+
+	for _, point := range shapeEDN.Points {
+		if len(point) >= 2 {
+			shape.Points = append(shape.Points, graph.Point{X: point[0], Y: point[1]})
+		}
+	}
+
+	// Lines only store their end points as handles.
+	for _, handleName := range []edn.Keyword{"start", "end"} {
+		handle, ok := shapeEDN.Handles[handleName]
+		if ok && len(handle.Point) >= 2 {
+			shape.Points = append(shape.Points, graph.Point{X: handle.Point[0], Y: handle.Point[1]})
+		}
+	}
+
+	if shapeEDN.Type == graph.ShapeTypePortal {
+		shape.PortalTarget = shapeEDN.PageID
+		shape.PortalIsPage = shapeEDN.BlockType != "B"
+	}
+
+	return shape
+}
+
+// shapeContent describes a shape as block content, using link syntax for portals.
+func shapeContent(shape graph.WhiteboardShape) string {
+	if shape.IsPortal() {
+		if shape.PortalIsPage {
+			return "[[" + shape.PortalTarget + "]]"
+		}
+
+		return "((" + shape.PortalTarget + "))"
+	}
+
+	return shape.Text
+}
+
+// markPortalEmbeds flags a portal block's links as embeds.
+func markPortalEmbeds(block *graph.Block) {
+	for path, link := range block.Content.Links {
+		if link.IsPage() || link.IsBlock() {
+			link.IsEmbed = true
+			block.Content.Links[path] = link
+		}
+	}
+}
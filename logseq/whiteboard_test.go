@@ -0,0 +1,97 @@
+package logseq_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/logseq"
+)
+
+const whiteboardEDN = `{:blocks ({:block/content ""
+   :block/properties {:ls-type :whiteboard-shape
+                      :logseq.tldraw.shape {:blockType "P" :id "s1" :type "logseq-portal" :pageId "Other Page"
+                                            :point [100 200] :size [400 160]}}
+   :block/uuid #uuid "6561b1c4-0000-4000-8000-000000000001"}
+  {:block/properties {:ls-type :whiteboard-shape
+                      :logseq.tldraw.shape {:id "s2" :type "text" :text "Ideas for #planning" :point [10 20] :size [120 30]}}
+   :block/uuid #uuid "6561b1c4-0000-4000-8000-000000000002"}
+  {:block/properties {:ls-type :whiteboard-shape
+                      :logseq.tldraw.shape {:id "s3" :type "line" :point [0 0] :size [50 50]
+                                            :handles {:start {:id "start" :point [0 0]} :end {:id "end" :point [50 50]}}}}
+   :block/uuid #uuid "6561b1c4-0000-4000-8000-000000000003"})
+ :pages ({:block/name "plan"
+          :block/original-name "Plan"
+          :block/properties {:ls-type :whiteboard-page :logseq.tldraw.page {:id "p"} :public true}
+          :block/type "whiteboard"})}`
+
+func TestLoader_LoadGraph_Whiteboard(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "pages/Other Page.md", "- other")
+	writeGraphFile(t, graphDir, "whiteboards/plan.edn", whiteboardEDN)
+
+	g, _, err := logseq.LoadGraph(graphDir, false)
+
+	require.NoError(t, err)
+
+	page, err := g.FindPage("Plan")
+	require.NoError(t, err)
+	assert.True(t, page.IsWhiteboard())
+	assert.True(t, page.IsPublic())
+	assert.Equal(t, "whiteboards", page.Namespace)
+
+	shapes := page.Whiteboard.Shapes
+	require.Len(t, shapes, 3)
+	assert.Equal(t, "Other Page", shapes[0].PortalTarget)
+	assert.True(t, shapes[0].PortalIsPage)
+	assert.Equal(t, graph.Point{X: 10, Y: 20}, graph.Point{X: shapes[1].X, Y: shapes[1].Y})
+	assert.Equal(t, []graph.Point{{X: 0, Y: 0}, {X: 50, Y: 50}}, shapes[2].Points)
+
+	require.Len(t, page.Root.Children, 2)
+	portalBlock := page.Root.Children[0]
+	assert.Equal(t, "6561b1c4-0000-4000-8000-000000000001", portalBlock.ID)
+
+	links := portalBlock.Links()
+	require.Len(t, links, 1)
+	assert.Equal(t, "Other Page", links[0].LinkPath)
+	assert.True(t, links[0].IsEmbed)
+
+	other, err := g.FindPage("Other Page")
+	require.NoError(t, err)
+	assert.Len(t, other.Backlinks, 1)
+}
+
+func TestLoader_LoadWhiteboard_PageProperties(t *testing.T) {
+	graphDir := t.TempDir()
+	writeGraphFile(t, graphDir, "whiteboards/props.edn", `{:blocks ()
+ :pages ({:block/original-name "Props"
+          :block/properties {:public true :tags #{"zeta" "alpha"} :sources ["one" "two"]
+                             :status :draft :meta {:k 1}}})}`)
+
+	rootID := func() string {
+		g, _, err := logseq.LoadGraph(graphDir, false)
+		require.NoError(t, err)
+
+		page, err := g.FindPage("Props")
+		require.NoError(t, err)
+
+		tags, _ := page.Root.Properties.Get("tags")
+		assert.Equal(t, "[[alpha]], [[zeta]]", tags.Value)
+		assert.Equal(t, []string{"alpha", "zeta"}, page.Tags())
+
+		sources, _ := page.Root.Properties.Get("sources")
+		assert.Equal(t, "one, two", sources.Value)
+
+		status, _ := page.Root.Properties.Get("status")
+		assert.Equal(t, "draft", status.Value)
+
+		_, ok := page.Root.Properties.Get("meta")
+		assert.False(t, ok)
+
+		return page.Root.ID
+	}
+
+	assert.Equal(t, rootID(), rootID())
+}
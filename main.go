@@ -1,6 +1,7 @@
 package main
 
 import (
+	"os"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -8,8 +9,10 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"export-logseq/graph"
 	"export-logseq/hugo"
 	"export-logseq/logseq"
+	"export-logseq/timereport"
 )
 
 type EnvFlag string
@@ -35,29 +38,123 @@ const (
 )
 
 type ExportCmd struct {
-	GraphDir      string        `arg:""           env:"GRAPH_DIR"   help:"Path to the Logseq graph directory."`
-	SiteDir       string        `arg:""           env:"SITE_DIR"    help:"Path to the site directory."`
-	SelectedPages SelectedPages `default:"public" enum:"all,public" help:"Select pages to export."`
+	GraphDir      string          `arg:""           env:"GRAPH_DIR"   help:"Path to the Logseq graph directory."`
+	SiteDir       string          `arg:""           env:"SITE_DIR"    help:"Path to the site directory."`
+	SelectedPages SelectedPages   `default:"public" enum:"all,public" help:"Select pages to export."`
+	Lenient       bool            `help:"Skip pages that fail to load instead of stopping the export."`
+	History       bool            `help:"Load page history from logseq/bak and logseq/version-files."`
+	Tasks         hugo.TaskPolicy `default:"hide"   enum:"hide,show,show-done-only" help:"Select task blocks to export."`
+	Calendar      bool            `help:"Write scheduled and deadline blocks to static/logseq.ics."`
+	SiteURL       string          `env:"SITE_URL"   help:"Base URL of the published site, used for links in the calendar."`
+	PublishPDFs   bool            `name:"publish-pdfs" help:"Copy PDF assets to the site and link PDF annotations to them."`
+	Timezone      string          `default:"Local"  help:"Time zone for task timestamps and clock entries, like Europe/Berlin."`
 }
 
 func (cmd *ExportCmd) Run() error {
-	graph, err := logseq.LoadGraph(cmd.GraphDir)
+	location, err := time.LoadLocation(cmd.Timezone)
+	if err != nil {
+		return errors.Wrap(err, "loading time zone")
+	}
+
+	graph, report, err := loadGraph(cmd.GraphDir, cmd.Lenient, location)
 
 	if err != nil {
 		return errors.Wrap(err, "loading graph")
 	}
 
-	requirePublic := cmd.SelectedPages == PublicPages
-	if err := hugo.ExportGraph(graph, cmd.SiteDir, requirePublic); err != nil {
+	if report.HasFailures() {
+		log.Warn(report.String())
+	}
+
+	if cmd.History {
+		if err := logseq.LoadHistory(&graph); err != nil {
+			return errors.Wrap(err, "loading page history")
+		}
+	}
+
+	options := hugo.ExportOptions{
+		RequirePublic: cmd.SelectedPages == PublicPages,
+		TaskPolicy:    cmd.Tasks,
+		Calendar:      cmd.Calendar,
+		SiteURL:       cmd.SiteURL,
+		PublishPDFs:   cmd.PublishPDFs,
+		Location:      location,
+	}
+
+	if err := hugo.ExportGraph(graph, cmd.SiteDir, options); err != nil {
 		return errors.Wrap(err, "exporting graph")
 	}
 
 	return nil
 }
 
+type TimeReportCmd struct {
+	GraphDir string             `arg:""          env:"GRAPH_DIR"                    help:"Path to the Logseq graph directory."`
+	GroupBy  timereport.GroupBy `default:"page"  enum:"page,tag,namespace,day,week" help:"Group logged time by page, tag, namespace, day or week."`
+	Format   timereport.Format  `default:"table" enum:"table,csv,json"              help:"Output format."`
+	Since    string             `help:"Only count clock entries starting on or after this date (YYYY-MM-DD)."`
+	Until    string             `help:"Only count clock entries starting before this date (YYYY-MM-DD)."`
+	Lenient  bool               `help:"Skip pages that fail to load instead of stopping the report."`
+	Timezone string             `default:"Local" help:"Time zone for clock entries and report dates, like Europe/Berlin."`
+}
+
+func (cmd *TimeReportCmd) Run() error {
+	location, err := time.LoadLocation(cmd.Timezone)
+	if err != nil {
+		return errors.Wrap(err, "loading time zone")
+	}
+
+	since, err := parseReportDate(cmd.Since, location)
+	if err != nil {
+		return errors.Wrap(err, "parsing --since")
+	}
+
+	until, err := parseReportDate(cmd.Until, location)
+	if err != nil {
+		return errors.Wrap(err, "parsing --until")
+	}
+
+	graph, report, err := loadGraph(cmd.GraphDir, cmd.Lenient, location)
+	if err != nil {
+		return errors.Wrap(err, "loading graph")
+	}
+
+	if report.HasFailures() {
+		log.Warn(report.String())
+	}
+
+	entries := timereport.Collect(&graph, since, until)
+	timeReport := timereport.Summarize(entries, cmd.GroupBy)
+
+	if err := timereport.Write(os.Stdout, timeReport, cmd.Format); err != nil {
+		return errors.Wrap(err, "writing time report")
+	}
+
+	return nil
+}
+
+// parseReportDate reads an optional date from the command line.
+func parseReportDate(text string, location *time.Location) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", text, location)
+}
+
+// loadGraph loads a Logseq graph, reading task times in location.
+func loadGraph(graphDir string, lenient bool, location *time.Location) (graph.Graph, logseq.LoadReport, error) {
+	loader := logseq.NewLoader(graphDir)
+	loader.Lenient = lenient
+	loader.Location = location
+
+	return loader.Load()
+}
+
 type CLI struct {
-	EnvFile EnvFlag
-	Export  ExportCmd `cmd:"" help:"Export a Logseq graph to SSG content folder."`
+	EnvFile    EnvFlag
+	Export     ExportCmd     `cmd:"" help:"Export a Logseq graph to SSG content folder."`
+	TimeReport TimeReportCmd `cmd:"" help:"Report time logged in task LOGBOOK clock entries."`
 }
 
 func main() {
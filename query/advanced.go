@@ -0,0 +1,266 @@
+package query
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"olympos.io/encoding/edn"
+
+	"export-logseq/graph"
+)
+
+// AdvancedQuery is a parsed #+BEGIN_QUERY block. It holds either a Datalog query
+// or, like Logseq allows, a simple query in the :query slot.
+type AdvancedQuery struct {
+	Title     string
+	simple    *Query
+	findVar   string
+	inVars    []string
+	inputs    []any
+	where     []clause
+	transform *sortTransform
+}
+
+// ParseAdvanced reads the EDN map of an advanced query. Forms that can't be evaluated here
+// are reported as errors, so they aren't quietly mis-rendered.
+func ParseAdvanced(text string) (*AdvancedQuery, error) {
+	fields := map[edn.Keyword]edn.RawMessage{}
+	if err := edn.UnmarshalString(text, &fields); err != nil {
+		return nil, errors.Wrap(err, "reading query EDN")
+	}
+
+	q := AdvancedQuery{}
+
+	for key := range fields {
+		switch key {
+		case "query", "title", "inputs", "result-transform", "collapsed?", "group-by-page?", "breadcrumb-show?":
+		default:
+			return nil, errors.Errorf("unsupported option %s", key)
+		}
+	}
+
+	if rawTitle, ok := fields["title"]; ok {
+		var title any
+		if err := edn.Unmarshal(rawTitle, &title); err != nil {
+			return nil, errors.Wrap(err, "reading :title")
+		}
+
+		// Hiccup titles can't be shown as text, so they're left out.
+		if text, ok := title.(string); ok {
+			q.Title = text
+		}
+	}
+
+	rawQuery, ok := fields["query"]
+	if !ok {
+		return nil, errors.New("missing :query")
+	}
+
+	if err := q.parseQueryForm(bytes.TrimSpace(rawQuery)); err != nil {
+		return nil, err
+	}
+
+	if rawInputs, ok := fields["inputs"]; ok {
+		if err := edn.Unmarshal(rawInputs, &q.inputs); err != nil {
+			return nil, errors.Wrap(err, "reading :inputs")
+		}
+	}
+
+	if len(q.inputs) < len(q.inVars) {
+		return nil, errors.Errorf("query expects %d inputs but has %d", len(q.inVars), len(q.inputs))
+	}
+
+	if rawTransform, ok := fields["result-transform"]; ok {
+		var form any
+		if err := edn.Unmarshal(rawTransform, &form); err != nil {
+			return nil, errors.Wrap(err, "reading :result-transform")
+		}
+
+		transform, err := parseSortTransform(form)
+		if err != nil {
+			return nil, errors.Wrap(err, ":result-transform")
+		}
+
+		q.transform = transform
+	}
+
+	return &q, nil
+}
+
+// parseQueryForm reads the :query value, which is a Datalog vector, a simple query
+// list, or a simple query string.
+func (q *AdvancedQuery) parseQueryForm(raw []byte) error {
+	simpleText := ""
+
+	switch {
+	case bytes.HasPrefix(raw, []byte("[")):
+		var form []any
+		if err := edn.Unmarshal(raw, &form); err != nil {
+			return errors.Wrap(err, "reading :query")
+		}
+
+		return q.parseDatalog(form)
+	case bytes.HasPrefix(raw, []byte(`"`)):
+		if err := edn.Unmarshal(raw, &simpleText); err != nil {
+			return errors.Wrap(err, "reading :query")
+		}
+	default:
+		simpleText = string(raw)
+	}
+
+	simple, err := Parse(simpleText)
+	if err != nil {
+		return errors.Wrap(err, "reading simple :query")
+	}
+
+	q.simple = simple
+
+	return nil
+}
+
+// parseDatalog reads a [:find ... :in ... :where ...] vector.
+func (q *AdvancedQuery) parseDatalog(form []any) error {
+	sections := map[edn.Keyword][]any{}
+	section := edn.Keyword("")
+
+	for _, item := range form {
+		if keyword, ok := item.(edn.Keyword); ok {
+			section = keyword
+			sections[section] = []any{}
+
+			continue
+		}
+
+		if section == "" {
+			return errors.New(":query must start with :find")
+		}
+
+		sections[section] = append(sections[section], item)
+	}
+
+	for name := range sections {
+		if name != "find" && name != "in" && name != "where" {
+			return errors.Errorf("unsupported :query section %s", name)
+		}
+	}
+
+	findVar, err := parseFind(sections["find"])
+	if err != nil {
+		return err
+	}
+
+	q.findVar = findVar
+
+	for _, item := range sections["in"] {
+		symbol, ok := item.(edn.Symbol)
+		if !ok {
+			return errors.Errorf("unsupported :in binding %v", item)
+		}
+
+		if symbol == "$" {
+			continue
+		}
+
+		if symbol == "%" {
+			return errors.New("custom rules aren't supported")
+		}
+
+		q.inVars = append(q.inVars, string(symbol))
+	}
+
+	if len(sections["where"]) == 0 {
+		return errors.New("missing :where")
+	}
+
+	where, err := parseClauses(sections["where"])
+	if err != nil {
+		return errors.Wrap(err, ":where")
+	}
+
+	q.where = where
+
+	return nil
+}
+
+// parseFind reads a :find of one entity variable, pulled or bare.
+func parseFind(find []any) (string, error) {
+	if len(find) != 1 {
+		return "", errors.New(":find must have exactly one entity")
+	}
+
+	switch item := find[0].(type) {
+	case edn.Symbol:
+		if isVariable(item) {
+			return string(item), nil
+		}
+	case []any:
+		if len(item) == 3 && item[0] == edn.Symbol("pull") {
+			if variable, ok := item[1].(edn.Symbol); ok && isVariable(variable) {
+				return string(variable), nil
+			}
+		}
+	}
+
+	return "", errors.Errorf("unsupported :find %v", find[0])
+}
+
+// Run evaluates the query against a graph. now anchors relative date inputs like :today,
+// and currentPage is the page holding the query, for the :current-page input.
+func (q *AdvancedQuery) Run(g *graph.Graph, now time.Time, currentPage string) (Result, error) {
+	if q.simple != nil {
+		return q.simple.Run(g, now), nil
+	}
+
+	eval := newDatalogEvaluation(g, now)
+	start := binding{}
+
+	for i, name := range q.inVars {
+		value, err := eval.inputValue(q.inputs[i], currentPage)
+		if err != nil {
+			return Result{}, errors.Wrap(err, "reading :inputs")
+		}
+
+		start[name] = value
+	}
+
+	bindings, err := applyClauses(eval, q.where, []binding{start})
+	if err != nil {
+		return Result{}, err
+	}
+
+	found := map[any]bool{}
+
+	for _, b := range bindings {
+		switch value := b[q.findVar].(type) {
+		case *graph.Page, *graph.Block:
+			found[value] = true
+		}
+	}
+
+	result := Result{Pages: []*graph.Page{}, Blocks: []*graph.Block{}}
+
+	for _, entity := range eval.entities {
+		if !found[entity] {
+			continue
+		}
+
+		switch e := entity.(type) {
+		case *graph.Page:
+			result.Pages = append(result.Pages, e)
+		case *graph.Block:
+			result.Blocks = append(result.Blocks, e)
+		}
+	}
+
+	if q.transform != nil {
+		q.transform.apply(eval, &result)
+	}
+
+	return result, nil
+}
+
+func isVariable(symbol edn.Symbol) bool {
+	return strings.HasPrefix(string(symbol), "?")
+}
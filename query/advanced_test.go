@@ -0,0 +1,148 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/query"
+)
+
+func runAdvanced(t *testing.T, text string) query.Result {
+	t.Helper()
+
+	q, err := query.ParseAdvanced(text)
+	require.NoError(t, err, text)
+
+	result, err := q.Run(queryGraph(t), time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC), "Dashboard")
+	require.NoError(t, err, text)
+
+	return result
+}
+
+func TestAdvancedQuery_Blocks(t *testing.T) {
+	queryTests := []struct {
+		query string
+		want  []string
+	}{
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/marker ?m] [(contains? #{"TODO" "DOING"} ?m)]]}`,
+			[]string{"DOING review #Meetings", "TODO plan the week", "TODO [#A] Write the plan"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/page ?p] [?p :block/name "project x"] (not [?b :block/marker _])]}`,
+			[]string{"Notes about [[Meetings]]", "follow up with the team"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/refs ?r] [?r :block/name "meetings"]]}`,
+			[]string{"DOING review #Meetings", "Notes about [[Meetings]]"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/path-refs ?r] [?r :block/name "meetings"] [?b :block/content ?c] [(clojure.string/includes? ?c "team")]]}`,
+			[]string{"follow up with the team"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where (task ?b #{"TODO" "LATER"}) (page-tags ?p #{"project"}) [?b :block/page ?p]]}`,
+			[]string{"TODO [#A] Write the plan", "LATER [#B] Close out"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :in $ ?start ?end :where [?b :block/page ?p] [?p :block/journal-day ?d] [(>= ?d ?start)] [(<= ?d ?end)]]
+			  :inputs [:-7d :today]}`,
+			[]string{"TODO plan the week"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :in $ ?start ?end :where (between ?b ?start ?end)] :inputs [20240601 20240605]}`,
+			[]string{"DOING review #Meetings"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/properties ?props] [(get ?props :type) ?type] [(= ?type "book")]]}`,
+			[]string{"Dune"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where (or (priority ?b #{"A"}) (page-ref ?b "project y"))]}`,
+			[]string{"TODO [#A] Write the plan", "DONE Kick off [[Project Y]]"},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/marker _]]
+			  :result-transform (fn [result] (sort-by (fn [h] (get h :block/priority "Z")) result))}`,
+			[]string{
+				"TODO [#A] Write the plan", "LATER [#B] Close out",
+				"DOING review #Meetings", "TODO plan the week", "DONE Kick off [[Project Y]]",
+			},
+		},
+		{
+			`{:query [:find (pull ?b [*]) :where [?b :block/marker _]]
+			  :result-transform (fn [result] (reverse (sort-by :block/content result)))}`,
+			[]string{
+				"TODO plan the week", "TODO [#A] Write the plan", "LATER [#B] Close out",
+				"DONE Kick off [[Project Y]]", "DOING review #Meetings",
+			},
+		},
+		{
+			`{:title "Tasks" :query (and (task TODO) [[project y]])}`,
+			[]string{},
+		},
+		{
+			`{:query "(priority B)"}`,
+			[]string{"LATER [#B] Close out"},
+		},
+	}
+
+	for _, tt := range queryTests {
+		result := runAdvanced(t, tt.query)
+
+		assert.Empty(t, result.Pages, tt.query)
+		assert.Equal(t, tt.want, blockContents(result), tt.query)
+	}
+}
+
+func TestAdvancedQuery_Pages(t *testing.T) {
+	queryTests := []struct {
+		query string
+		want  []string
+	}{
+		{
+			`{:query [:find (pull ?p [*]) :where (page-property ?p :status "active")]}`,
+			[]string{"Project X"},
+		},
+		{
+			`{:query [:find ?p :where [?p :block/journal? true]]}`,
+			[]string{"2024-06-01", "2024-06-10"},
+		},
+		{
+			`{:query [:find (pull ?p [*]) :where [?p :block/tags ?t] [?t :block/name "archived"]]}`,
+			[]string{"Project Y"},
+		},
+		{
+			`{:query [:find (pull ?p [*]) :in $ ?current :where [?b :block/page ?p] [?b :block/refs ?r] [?r :block/name ?current]]
+			  :inputs [:current-page]}`,
+			[]string{},
+		},
+	}
+
+	for _, tt := range queryTests {
+		result := runAdvanced(t, tt.query)
+
+		assert.Empty(t, result.Blocks, tt.query)
+		assert.Equal(t, tt.want, pageNames(result), tt.query)
+	}
+}
+
+func TestParseAdvanced_Unsupported(t *testing.T) {
+	for _, text := range []string{
+		`{:query [:find ?b :where [?b :block/marker _]] :view (fn [r] [:div r])}`,
+		`{:query [:find ?b ?c :where [?b :block/content ?c]]}`,
+		`{:query [:find ?b :in $ % :where (custom ?b)]}`,
+		`{:query [:find ?b :where (custom-rule ?b)]}`,
+		`{:query [:find ?b :where [(missing? $ ?b :block/marker)]]}`,
+		`{:query [:find ?b :where [?b :block/marker _]] :result-transform (fn [r] (map :block/content r))}`,
+		`{:title "no query"}`,
+		`{:query [:find ?b :where`,
+	} {
+		_, err := query.ParseAdvanced(text)
+
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,853 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"olympos.io/encoding/edn"
+
+	"export-logseq/graph"
+)
+
+// binding maps Datalog variables to values: pages, blocks, property maps, strings,
+// int64 and float64 numbers, bools, or string slices for multi-valued properties.
+type binding map[string]any
+
+func (b binding) with(name string, value any) binding {
+	extended := binding{}
+
+	for k, v := range b {
+		extended[k] = v
+	}
+
+	extended[name] = value
+
+	return extended
+}
+
+// term is a clause argument: a variable, a constant, or the _ wildcard.
+type term struct {
+	variable string
+	constant any
+	wildcard bool
+}
+
+func newTerm(item any) term {
+	if symbol, ok := item.(edn.Symbol); ok {
+		if symbol == "_" {
+			return term{wildcard: true}
+		}
+
+		if isVariable(symbol) {
+			return term{variable: string(symbol)}
+		}
+	}
+
+	return term{constant: item}
+}
+
+// resolve returns the term's value in a binding, if it has one.
+func (t term) resolve(b binding) (any, bool) {
+	if t.wildcard {
+		return nil, false
+	}
+
+	if t.variable == "" {
+		return t.constant, true
+	}
+
+	value, ok := b[t.variable]
+
+	return value, ok
+}
+
+// unify matches a value against the term, binding it if the term is an unbound variable.
+func (t term) unify(b binding, value any) (binding, bool) {
+	if t.wildcard {
+		return b, true
+	}
+
+	if bound, ok := t.resolve(b); ok {
+		return b, valuesEqual(bound, value)
+	}
+
+	return b.with(t.variable, value), true
+}
+
+// datalogEvaluation holds the graph's entities while a Datalog query runs.
+type datalogEvaluation struct {
+	*evaluation
+	entities []any // Pages, then their blocks, in page name order
+	pageOf   map[*graph.Block]*graph.Page
+}
+
+func newDatalogEvaluation(g *graph.Graph, now time.Time) *datalogEvaluation {
+	eval := datalogEvaluation{
+		evaluation: &evaluation{graph: g, now: now, refs: map[string]map[string]bool{}},
+		pageOf:     map[*graph.Block]*graph.Page{},
+	}
+
+	pages := sortedPages(g)
+
+	for _, page := range pages {
+		eval.entities = append(eval.entities, page)
+	}
+
+	for _, page := range pages {
+		for _, block := range page.AllBlocks {
+			eval.pageOf[block] = page
+
+			// The root block's properties belong to the page entity.
+			if block.Depth > 0 {
+				eval.entities = append(eval.entities, block)
+			}
+		}
+	}
+
+	return &eval
+}
+
+// inputValue resolves an :inputs value. Date keywords like :today and :-7d become
+// journal days, and :current-page becomes the lowercased name of the query's page.
+func (e *datalogEvaluation) inputValue(input any, currentPage string) (any, error) {
+	keyword, ok := input.(edn.Keyword)
+	if !ok {
+		return input, nil
+	}
+
+	if keyword == "current-page" || keyword == "query-page" {
+		return strings.ToLower(currentPage), nil
+	}
+
+	day, ok := e.resolveDay(string(keyword))
+	if !ok {
+		return nil, errors.Errorf("unsupported input %s", keyword)
+	}
+
+	return dayNumber(day), nil
+}
+
+// dayNumber writes a date the way Logseq stores journal days, like 20240603.
+func dayNumber(day time.Time) int64 {
+	return int64(day.Year()*10000 + int(day.Month())*100 + day.Day())
+}
+
+// attribute returns the values of an entity attribute, like :block/page or :block/refs.
+func (e *datalogEvaluation) attribute(entity any, attr edn.Keyword) []any {
+	switch entity := entity.(type) {
+	case *graph.Page:
+		return e.pageAttribute(entity, attr)
+	case *graph.Block:
+		return e.blockAttribute(entity, attr)
+	default:
+		return nil
+	}
+}
+
+func (e *datalogEvaluation) pageAttribute(page *graph.Page, attr edn.Keyword) []any {
+	switch attr {
+	case "block/name":
+		return []any{strings.ToLower(page.Name)}
+	case "block/original-name":
+		return []any{page.Name}
+	case "block/uuid":
+		return []any{page.Root.ID}
+	case "block/properties":
+		return []any{page.Root.Properties}
+	case "block/journal?":
+		return []any{page.IsJournal()}
+	case "block/journal-day":
+		if day, ok := journalDay(page); ok {
+			return []any{dayNumber(day)}
+		}
+	case "block/tags":
+		return e.pageRefs(page.Tags())
+	case "block/alias":
+		return e.pageRefs(page.Aliases())
+	case "block/namespace":
+		if index := strings.LastIndex(page.Name, "/"); index > 0 {
+			return e.pageRefs([]string{page.Name[:index]})
+		}
+	}
+
+	return nil
+}
+
+func (e *datalogEvaluation) blockAttribute(block *graph.Block, attr edn.Keyword) []any {
+	switch attr {
+	case "block/page":
+		if page, ok := e.pageOf[block]; ok {
+			return []any{page}
+		}
+	case "block/parent":
+		if block.Parent == nil {
+			return nil
+		}
+
+		if block.Parent.Depth == 0 {
+			return e.blockAttribute(block, "block/page")
+		}
+
+		return []any{block.Parent}
+	case "block/uuid":
+		return []any{block.ID}
+	case "block/content":
+		return []any{block.Content.Markdown}
+	case "block/properties":
+		return []any{block.Properties}
+	case "block/refs":
+		return e.blockRefs(block)
+	case "block/path-refs":
+		refs := e.blockAttribute(block, "block/page")
+
+		for ancestor := block; ancestor != nil && ancestor.Depth > 0; ancestor = ancestor.Parent {
+			refs = append(refs, e.blockRefs(ancestor)...)
+		}
+
+		return refs
+	case "block/marker":
+		if block.Task != nil {
+			return []any{string(block.Task.Marker)}
+		}
+	case "block/priority":
+		if block.Task != nil && block.Task.Priority != "" {
+			return []any{block.Task.Priority}
+		}
+	case "block/scheduled", "block/deadline":
+		scheduled, deadline := block.Planning()
+		if attr == "block/deadline" {
+			scheduled = deadline
+		}
+
+		if scheduled != nil {
+			return []any{dayNumber(scheduled.Time)}
+		}
+	}
+
+	return nil
+}
+
+// blockRefs returns the pages a block links to.
+func (e *datalogEvaluation) blockRefs(block *graph.Block) []any {
+	names := []string{}
+
+	for _, link := range block.Links() {
+		if link.IsPage() || link.IsTag() {
+			names = append(names, link.LinkPath)
+		}
+	}
+
+	return e.pageRefs(names)
+}
+
+// pageRefs returns the graph pages with the given names, skipping any that don't exist.
+func (e *datalogEvaluation) pageRefs(names []string) []any {
+	pages := []any{}
+
+	for _, name := range names {
+		if page, err := e.graph.FindPage(name); err == nil {
+			pages = append(pages, page)
+		}
+	}
+
+	return pages
+}
+
+// propertyValue reads a property the way Logseq queries see it: numbers and booleans
+// are typed, lists are string slices, and anything else is a string.
+func propertyValue(properties *graph.PropertyMap, name string) (any, bool) {
+	property, ok := properties.Get(name)
+	if !ok {
+		return nil, false
+	}
+
+	parsed := property.Parsed()
+
+	switch parsed.Type {
+	case graph.PropertyTypeNumber, graph.PropertyTypeBool:
+		return parsed.Value, true
+	case graph.PropertyTypeList:
+		items := []string{}
+
+		for _, item := range parsed.Items() {
+			items = append(items, strings.ToLower(item.String()))
+		}
+
+		return items, true
+	default:
+		return parsed.String(), true
+	}
+}
+
+// clause is one :where clause, narrowing or extending a set of bindings.
+type clause interface {
+	apply(eval *datalogEvaluation, bindings []binding) ([]binding, error)
+}
+
+func applyClauses(eval *datalogEvaluation, clauses []clause, bindings []binding) ([]binding, error) {
+	var err error
+
+	for _, c := range clauses {
+		bindings, err = c.apply(eval, bindings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bindings, nil
+}
+
+func parseClauses(forms []any) ([]clause, error) {
+	clauses := []clause{}
+
+	for _, form := range forms {
+		c, err := parseClause(form)
+		if err != nil {
+			return nil, err
+		}
+
+		clauses = append(clauses, c)
+	}
+
+	return clauses, nil
+}
+
+// parseClause reads a :where clause. EDN lists and vectors decode alike, so clauses are told
+// apart by their first item: a variable starts a data pattern, a list starts a predicate or
+// function call, and a plain symbol names a rule or a not/or/and form.
+func parseClause(form any) (clause, error) {
+	items, ok := form.([]any)
+	if !ok || len(items) == 0 {
+		return nil, errors.Errorf("unsupported clause %v", form)
+	}
+
+	switch first := items[0].(type) {
+	case []any:
+		return parseCallClause(first, items[1:])
+	case edn.Symbol:
+		if isVariable(first) || first == "_" {
+			return parsePatternClause(items)
+		}
+
+		return parseRuleClause(first, items[1:])
+	default:
+		return nil, errors.Errorf("unsupported clause %v", form)
+	}
+}
+
+// patternClause is a data pattern like [?b :block/page ?p].
+type patternClause struct {
+	entity term
+	attr   edn.Keyword
+	value  term
+}
+
+func parsePatternClause(items []any) (clause, error) {
+	if len(items) < 2 || len(items) > 3 {
+		return nil, errors.Errorf("unsupported data pattern %v", items)
+	}
+
+	attr, ok := items[1].(edn.Keyword)
+	if !ok {
+		return nil, errors.Errorf("data pattern attribute must be a keyword: %v", items)
+	}
+
+	c := patternClause{entity: newTerm(items[0]), attr: attr, value: term{wildcard: true}}
+	if len(items) == 3 {
+		c.value = newTerm(items[2])
+	}
+
+	return c, nil
+}
+
+func (c patternClause) apply(eval *datalogEvaluation, bindings []binding) ([]binding, error) {
+	results := []binding{}
+
+	for _, b := range bindings {
+		candidates := eval.entities
+		if entity, ok := c.entity.resolve(b); ok {
+			candidates = []any{entity}
+		}
+
+		for _, entity := range candidates {
+			withEntity, ok := c.entity.unify(b, entity)
+			if !ok {
+				continue
+			}
+
+			for _, value := range eval.attribute(entity, c.attr) {
+				if matched, ok := c.value.unify(withEntity, value); ok {
+					results = append(results, matched)
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// callClause is a predicate like [(> ?d 20240101)], or a function binding like
+// [(get ?props :type) ?type].
+type callClause struct {
+	fn     string
+	args   []term
+	output term
+	bind   bool
+}
+
+func parseCallClause(call []any, rest []any) (clause, error) {
+	if len(call) == 0 {
+		return nil, errors.New("empty function call")
+	}
+
+	fn, ok := call[0].(edn.Symbol)
+	if !ok {
+		return nil, errors.Errorf("unsupported function %v", call[0])
+	}
+
+	c := callClause{fn: strings.TrimPrefix(strings.TrimPrefix(string(fn), "clojure.string/"), "str/")}
+
+	for _, arg := range call[1:] {
+		c.args = append(c.args, newTerm(arg))
+	}
+
+	switch len(rest) {
+	case 0:
+		if _, ok := predicates[c.fn]; !ok {
+			return nil, errors.Errorf("unsupported predicate %s", fn)
+		}
+	case 1:
+		if c.fn != "get" && c.fn != "get-else" {
+			return nil, errors.Errorf("unsupported function %s", fn)
+		}
+
+		if c.fn == "get-else" {
+			return nil, errors.New("unsupported function get-else")
+		}
+
+		c.output, c.bind = newTerm(rest[0]), true
+	default:
+		return nil, errors.Errorf("unsupported function binding %v", rest)
+	}
+
+	return c, nil
+}
+
+func (c callClause) apply(_ *datalogEvaluation, bindings []binding) ([]binding, error) {
+	results := []binding{}
+
+	for _, b := range bindings {
+		args := []any{}
+
+		for _, arg := range c.args {
+			value, ok := arg.resolve(b)
+			if !ok {
+				return nil, errors.Errorf("unbound variable %s in %s", arg.variable, c.fn)
+			}
+
+			args = append(args, value)
+		}
+
+		if !c.bind {
+			if predicates[c.fn](args) {
+				results = append(results, b)
+			}
+
+			continue
+		}
+
+		value, ok := getValue(args)
+		if !ok {
+			continue
+		}
+
+		if matched, ok := c.output.unify(b, value); ok {
+			results = append(results, matched)
+		}
+	}
+
+	return results, nil
+}
+
+// getValue implements (get map key default?) for property maps.
+func getValue(args []any) (any, bool) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, false
+	}
+
+	properties, ok := args[0].(*graph.PropertyMap)
+	if !ok {
+		return nil, false
+	}
+
+	key, ok := args[1].(edn.Keyword)
+	if !ok {
+		return nil, false
+	}
+
+	if value, ok := propertyValue(properties, string(key)); ok {
+		return value, true
+	}
+
+	if len(args) == 3 {
+		return args[2], true
+	}
+
+	return nil, false
+}
+
+var predicates = map[string]func(args []any) bool{
+	"=":            func(args []any) bool { return len(args) == 2 && valuesEqual(args[0], args[1]) },
+	"not=":         func(args []any) bool { return len(args) == 2 && !valuesEqual(args[0], args[1]) },
+	"<":            func(args []any) bool { return compareArgs(args, func(c int) bool { return c < 0 }) },
+	">":            func(args []any) bool { return compareArgs(args, func(c int) bool { return c > 0 }) },
+	"<=":           func(args []any) bool { return compareArgs(args, func(c int) bool { return c <= 0 }) },
+	">=":           func(args []any) bool { return compareArgs(args, func(c int) bool { return c >= 0 }) },
+	"contains?":    func(args []any) bool { return len(args) == 2 && collectionContains(args[0], args[1]) },
+	"includes?":    stringPredicate(strings.Contains),
+	"starts-with?": stringPredicate(strings.HasPrefix),
+	"ends-with?":   stringPredicate(strings.HasSuffix),
+}
+
+func compareArgs(args []any, test func(int) bool) bool {
+	if len(args) != 2 {
+		return false
+	}
+
+	c, ok := compareValues(args[0], args[1])
+
+	return ok && test(c)
+}
+
+func stringPredicate(test func(s, substr string) bool) func(args []any) bool {
+	return func(args []any) bool {
+		if len(args) != 2 {
+			return false
+		}
+
+		s, ok := args[0].(string)
+		if !ok {
+			return false
+		}
+
+		substr, ok := args[1].(string)
+
+		return ok && test(s, substr)
+	}
+}
+
+// collectionContains implements contains? for EDN sets and property lists.
+func collectionContains(collection any, value any) bool {
+	switch collection := collection.(type) {
+	case map[any]bool:
+		for item := range collection {
+			if valuesEqual(item, value) {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range collection {
+			if valuesEqual(item, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ruleClause calls one of Logseq's built-in query rules, like (task ?b #{"TODO"}).
+// Rules reuse the simple query filters.
+type ruleClause struct {
+	name   string
+	entity term
+	args   []term
+}
+
+// Rules and whether they match pages instead of blocks.
+var rules = map[string]bool{
+	"page-property": true,
+	"page-tags":     true,
+	"property":      false,
+	"task":          false,
+	"priority":      false,
+	"page-ref":      false,
+	"page":          false,
+	"between":       false,
+}
+
+func parseRuleClause(name edn.Symbol, args []any) (clause, error) {
+	switch name {
+	case "not", "or", "and", "not-join", "or-join":
+		return parseBooleanClause(string(name), args)
+	}
+
+	if _, ok := rules[string(name)]; !ok {
+		return nil, errors.Errorf("unsupported rule %s", name)
+	}
+
+	if len(args) < 2 {
+		return nil, errors.Errorf("rule %s needs an entity and arguments", name)
+	}
+
+	c := ruleClause{name: string(name), entity: newTerm(args[0])}
+
+	for _, arg := range args[1:] {
+		c.args = append(c.args, newTerm(arg))
+	}
+
+	return c, nil
+}
+
+func (c ruleClause) apply(eval *datalogEvaluation, bindings []binding) ([]binding, error) {
+	results := []binding{}
+
+	for _, b := range bindings {
+		args := []string{}
+
+		for _, arg := range c.args {
+			value, ok := arg.resolve(b)
+			if !ok {
+				return nil, errors.Errorf("unbound variable %s in %s", arg.variable, c.name)
+			}
+
+			args = append(args, ruleArgStrings(value)...)
+		}
+
+		f, err := ruleFilter(c.name, args)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := eval.entities
+		if entity, ok := c.entity.resolve(b); ok {
+			candidates = []any{entity}
+		}
+
+		for _, entity := range candidates {
+			if !eval.ruleMatches(f, c.name, entity) {
+				continue
+			}
+
+			if matched, ok := c.entity.unify(b, entity); ok {
+				results = append(results, matched)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (e *datalogEvaluation) ruleMatches(f filter, name string, entity any) bool {
+	switch entity := entity.(type) {
+	case *graph.Page:
+		return rules[name] && f.match(e.evaluation, entity, nil)
+	case *graph.Block:
+		return !rules[name] && f.match(e.evaluation, e.pageOf[entity], entity)
+	default:
+		return false
+	}
+}
+
+// ruleArgStrings flattens a rule argument into strings, the way simple query filters take them.
+func ruleArgStrings(value any) []string {
+	switch value := value.(type) {
+	case map[any]bool:
+		items := []string{}
+		for item := range value {
+			items = append(items, ruleArgStrings(item)...)
+		}
+
+		return items
+	case []string:
+		return value
+	case edn.Keyword:
+		return []string{string(value)}
+	case string:
+		return []string{value}
+	default:
+		return []string{fmt.Sprint(value)}
+	}
+}
+
+// ruleFilter builds the simple query filter for a rule.
+func ruleFilter(name string, args []string) (filter, error) {
+	switch name {
+	case "page-property", "property":
+		if len(args) != 2 {
+			return nil, errors.Errorf("%s needs a key and a value", name)
+		}
+
+		return propertyFilter{
+			name:     strings.TrimPrefix(args[0], ":"),
+			value:    strings.ToLower(args[1]),
+			hasValue: true,
+			onPage:   name == "page-property",
+		}, nil
+	case "page-tags":
+		return pageTagsFilter{lowerAll(args)}, nil
+	case "task":
+		return taskFilter{upperAll(args)}, nil
+	case "priority":
+		return priorityFilter{upperAll(args)}, nil
+	case "page-ref":
+		if len(args) != 1 {
+			return nil, errors.New("page-ref needs a page name")
+		}
+
+		return refFilter{strings.ToLower(args[0])}, nil
+	case "page":
+		return pageFilter{lowerAll(args)}, nil
+	case "between":
+		if len(args) != 2 {
+			return nil, errors.New("between needs a start and an end")
+		}
+
+		return betweenFilter{start: args[0], end: args[1]}, nil
+	default:
+		return nil, errors.Errorf("unsupported rule %s", name)
+	}
+}
+
+// booleanClause is a not, or, or and form. The join variants are treated like
+// their plain forms.
+type booleanClause struct {
+	op       string
+	branches [][]clause
+}
+
+func parseBooleanClause(op string, args []any) (clause, error) {
+	// The join variable list isn't needed, since bindings aren't projected.
+	if strings.HasSuffix(op, "-join") && len(args) > 0 {
+		op, args = strings.TrimSuffix(op, "-join"), args[1:]
+	}
+
+	if len(args) == 0 {
+		return nil, errors.Errorf("empty %s", op)
+	}
+
+	c := booleanClause{op: op}
+
+	if op != "or" {
+		clauses, err := parseClauses(args)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+
+		c.branches = [][]clause{clauses}
+
+		return c, nil
+	}
+
+	for _, arg := range args {
+		branch, err := parseClause(arg)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+
+		c.branches = append(c.branches, []clause{branch})
+	}
+
+	return c, nil
+}
+
+func (c booleanClause) apply(eval *datalogEvaluation, bindings []binding) ([]binding, error) {
+	switch c.op {
+	case "and":
+		return applyClauses(eval, c.branches[0], bindings)
+	case "not":
+		results := []binding{}
+
+		for _, b := range bindings {
+			matched, err := applyClauses(eval, c.branches[0], []binding{b})
+			if err != nil {
+				return nil, err
+			}
+
+			if len(matched) == 0 {
+				results = append(results, b)
+			}
+		}
+
+		return results, nil
+	default:
+		results := []binding{}
+
+		for _, branch := range c.branches {
+			matched, err := applyClauses(eval, branch, bindings)
+			if err != nil {
+				return nil, err
+			}
+
+			results = append(results, matched...)
+		}
+
+		return results, nil
+	}
+}
+
+// valuesEqual compares query values, treating numbers alike whatever their type
+// and strings without regard to case.
+func valuesEqual(a any, b any) bool {
+	if c, ok := compareValues(a, b); ok {
+		return c == 0
+	}
+
+	switch a := a.(type) {
+	case *graph.Page, *graph.Block, *graph.PropertyMap, bool:
+		return a == b
+	case edn.Keyword:
+		return valuesEqual(string(a), b)
+	}
+
+	if keyword, ok := b.(edn.Keyword); ok {
+		return valuesEqual(a, string(keyword))
+	}
+
+	return false
+}
+
+// compareValues orders two numbers or two strings.
+func compareValues(a any, b any) (int, bool) {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+
+		return 0, false
+	}
+
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+
+	return strings.Compare(strings.ToLower(x), strings.ToLower(y)), true
+}
+
+func toNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+
+	return 0, false
+}
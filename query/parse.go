@@ -0,0 +1,265 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tokenize splits simple query text into parentheses, quoted strings,
+// page references, tags, and bare words.
+func tokenize(text string) ([]string, error) {
+	tokens := []string{}
+
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				return nil, errors.Errorf("unterminated string at %d", i)
+			}
+
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		case strings.HasPrefix(text[i:], "[[") || strings.HasPrefix(text[i:], "#[["):
+			end := strings.Index(text[i:], "]]")
+			if end < 0 {
+				return nil, errors.Errorf("unterminated page reference at %d", i)
+			}
+
+			tokens = append(tokens, text[i:i+end+2])
+			i += end + 2
+		default:
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\n\r,()\"", rune(text[end])) {
+				end++
+			}
+
+			tokens = append(tokens, text[i:end])
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+// Parse reads a Logseq simple query, like the text inside {{query ...}}.
+// Several top level filters are combined with and.
+func Parse(text string) (*Query, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading query")
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	p := parser{tokens: tokens}
+	filters := []filter{}
+
+	for p.pos < len(p.tokens) {
+		f, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, f)
+	}
+
+	root := filters[0]
+	if len(filters) > 1 {
+		root = andFilter{filters}
+	}
+
+	return &Query{Text: strings.TrimSpace(text), root: root}, nil
+}
+
+func (p *parser) next() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+
+	return token, true
+}
+
+func (p *parser) parseFilter() (filter, error) {
+	token, ok := p.next()
+	if !ok {
+		return nil, errors.New("unexpected end of query")
+	}
+
+	switch {
+	case token == "(":
+		return p.parseList()
+	case token == ")":
+		return nil, errors.New("unexpected )")
+	case strings.HasPrefix(token, `"`):
+		return textFilter{strings.ToLower(unquote(token))}, nil
+	case strings.HasPrefix(token, "[[") || strings.HasPrefix(token, "#"):
+		return refFilter{strings.ToLower(argValue(token))}, nil
+	default:
+		return textFilter{strings.ToLower(token)}, nil
+	}
+}
+
+// parseList reads a parenthesized filter, after its opening parenthesis.
+func (p *parser) parseList() (filter, error) {
+	name, ok := p.next()
+	if !ok || name == ")" {
+		return nil, errors.New("empty filter")
+	}
+
+	name = strings.ToLower(name)
+
+	switch name {
+	case "and", "or", "not":
+		return p.parseBoolean(name)
+	}
+
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+
+	switch name {
+	case "page":
+		return pageFilter{lowerAll(args)}, nil
+	case "page-tags":
+		return pageTagsFilter{lowerAll(args)}, nil
+	case "property", "page-property":
+		if len(args) == 0 || len(args) > 2 {
+			return nil, errors.Errorf("%s needs a name and an optional value", name)
+		}
+
+		f := propertyFilter{name: strings.TrimPrefix(args[0], ":"), onPage: name == "page-property"}
+		if len(args) == 2 {
+			f.value, f.hasValue = strings.ToLower(args[1]), true
+		}
+
+		return f, nil
+	case "task":
+		return taskFilter{upperAll(args)}, nil
+	case "priority":
+		return priorityFilter{upperAll(args)}, nil
+	case "between":
+		if len(args) != 2 {
+			return nil, errors.New("between needs a start and an end")
+		}
+
+		return betweenFilter{start: args[0], end: args[1]}, nil
+	default:
+		return nil, errors.Errorf("unsupported filter %q", name)
+	}
+}
+
+func (p *parser) parseBoolean(name string) (filter, error) {
+	filters := []filter{}
+
+	for {
+		if p.pos >= len(p.tokens) {
+			return nil, errors.Errorf("unclosed %s", name)
+		}
+
+		if p.tokens[p.pos] == ")" {
+			p.pos++
+
+			break
+		}
+
+		f, err := p.parseFilter()
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+
+		filters = append(filters, f)
+	}
+
+	if len(filters) == 0 {
+		return nil, errors.Errorf("empty %s", name)
+	}
+
+	switch name {
+	case "and":
+		return andFilter{filters}, nil
+	case "or":
+		return orFilter{filters}, nil
+	default:
+		// Logseq treats (not a b) as not a and not b.
+		return notFilter{orFilter{filters}}, nil
+	}
+}
+
+// parseArgs reads plain filter arguments up to the closing parenthesis.
+func (p *parser) parseArgs() ([]string, error) {
+	args := []string{}
+
+	for {
+		token, ok := p.next()
+		if !ok {
+			return nil, errors.New("missing )")
+		}
+
+		if token == ")" {
+			return args, nil
+		}
+
+		if token == "(" {
+			return nil, errors.New("unexpected (")
+		}
+
+		args = append(args, argValue(token))
+	}
+}
+
+// argValue strips quotes and page reference syntax from a filter argument.
+func argValue(token string) string {
+	token = strings.TrimPrefix(token, "#")
+
+	if strings.HasPrefix(token, "[[") && strings.HasSuffix(token, "]]") {
+		return token[2 : len(token)-2]
+	}
+
+	return unquote(token)
+}
+
+func unquote(token string) string {
+	if len(token) >= 2 && strings.HasPrefix(token, `"`) && strings.HasSuffix(token, `"`) {
+		return token[1 : len(token)-1]
+	}
+
+	return token
+}
+
+func lowerAll(values []string) []string {
+	lowered := []string{}
+
+	for _, value := range values {
+		lowered = append(lowered, strings.ToLower(value))
+	}
+
+	return lowered
+}
+
+func upperAll(values []string) []string {
+	uppered := []string{}
+
+	for _, value := range values {
+		uppered = append(uppered, strings.ToUpper(value))
+	}
+
+	return uppered
+}
@@ -0,0 +1,386 @@
+// Package query evaluates Logseq simple queries against a graph.
+package query
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"export-logseq/graph"
+)
+
+// Query is a parsed simple query.
+type Query struct {
+	Text string
+	root filter
+}
+
+// Result holds what a query found. Queries made only of page filters like page-tags
+// find pages, and all other queries find blocks.
+type Result struct {
+	Pages  []*graph.Page
+	Blocks []*graph.Block
+}
+
+// IsPageResult returns true if the query looked for pages instead of blocks.
+func (q *Query) IsPageResult() bool {
+	return q.root.pageLevel()
+}
+
+// Run finds the pages or blocks in a graph that match the query.
+// now anchors relative dates like "today" and "-7d".
+func (q *Query) Run(g *graph.Graph, now time.Time) Result {
+	eval := evaluation{graph: g, now: now, refs: map[string]map[string]bool{}}
+	result := Result{Pages: []*graph.Page{}, Blocks: []*graph.Block{}}
+
+	for _, page := range sortedPages(g) {
+		if q.IsPageResult() {
+			if !page.IsPlaceholder() && q.root.match(&eval, page, nil) {
+				result.Pages = append(result.Pages, page)
+			}
+
+			continue
+		}
+
+		for _, block := range page.AllBlocks {
+			// The root only holds page properties, and query blocks would find themselves.
+			if block.Depth == 0 || queryMacroRe.MatchString(block.Content.Markdown) {
+				continue
+			}
+
+			if q.root.match(&eval, page, block) {
+				result.Blocks = append(result.Blocks, block)
+			}
+		}
+	}
+
+	return result
+}
+
+var queryMacroRe = regexp.MustCompile(`\{\{query\s`)
+
+func sortedPages(g *graph.Graph) []*graph.Page {
+	pages := []*graph.Page{}
+
+	for _, page := range g.Pages {
+		pages = append(pages, page)
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Name < pages[j].Name
+	})
+
+	return pages
+}
+
+// evaluation holds what filters need while a query runs.
+type evaluation struct {
+	graph *graph.Graph
+	now   time.Time
+	refs  map[string]map[string]bool // Lowercased names that refer to a page, by query reference
+}
+
+// refNames returns the lowercased names that refer to a page: its name and its aliases.
+func (e *evaluation) refNames(name string) map[string]bool {
+	if names, ok := e.refs[name]; ok {
+		return names
+	}
+
+	names := map[string]bool{name: true}
+
+	if page, err := e.graph.FindPage(name); err == nil {
+		names[strings.ToLower(page.Name)] = true
+
+		for _, alias := range page.Aliases() {
+			names[strings.ToLower(alias)] = true
+		}
+	}
+
+	e.refs[name] = names
+
+	return names
+}
+
+// filter is one term of a query. Page level filters are matched with a nil block
+// when the query looks for pages.
+type filter interface {
+	match(eval *evaluation, page *graph.Page, block *graph.Block) bool
+	pageLevel() bool
+}
+
+type andFilter struct{ filters []filter }
+
+func (f andFilter) match(eval *evaluation, page *graph.Page, block *graph.Block) bool {
+	for _, child := range f.filters {
+		if !child.match(eval, page, block) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f andFilter) pageLevel() bool { return allPageLevel(f.filters) }
+
+type orFilter struct{ filters []filter }
+
+func (f orFilter) match(eval *evaluation, page *graph.Page, block *graph.Block) bool {
+	for _, child := range f.filters {
+		if child.match(eval, page, block) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f orFilter) pageLevel() bool { return allPageLevel(f.filters) }
+
+type notFilter struct{ filter filter }
+
+func (f notFilter) match(eval *evaluation, page *graph.Page, block *graph.Block) bool {
+	return !f.filter.match(eval, page, block)
+}
+
+func (f notFilter) pageLevel() bool { return f.filter.pageLevel() }
+
+func allPageLevel(filters []filter) bool {
+	for _, f := range filters {
+		if !f.pageLevel() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// textFilter matches blocks containing some text, ignoring case.
+type textFilter struct{ text string }
+
+func (f textFilter) match(_ *evaluation, _ *graph.Page, block *graph.Block) bool {
+	return block != nil && strings.Contains(strings.ToLower(block.Content.Markdown), f.text)
+}
+
+func (f textFilter) pageLevel() bool { return false }
+
+// refFilter matches blocks that refer to a page, directly or through a parent block.
+type refFilter struct{ name string }
+
+func (f refFilter) match(eval *evaluation, _ *graph.Page, block *graph.Block) bool {
+	names := eval.refNames(f.name)
+
+	for ; block != nil; block = block.Parent {
+		for _, link := range block.Links() {
+			if (link.IsPage() || link.IsTag()) && names[strings.ToLower(link.LinkPath)] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (f refFilter) pageLevel() bool { return false }
+
+// pageFilter matches blocks on any of the named pages.
+type pageFilter struct{ names []string }
+
+func (f pageFilter) match(eval *evaluation, page *graph.Page, _ *graph.Block) bool {
+	for _, name := range f.names {
+		if eval.refNames(name)[strings.ToLower(page.Name)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f pageFilter) pageLevel() bool { return false }
+
+// pageTagsFilter matches pages tagged with any of the named tags.
+type pageTagsFilter struct{ tags []string }
+
+func (f pageTagsFilter) match(eval *evaluation, page *graph.Page, _ *graph.Block) bool {
+	for _, tag := range page.Tags() {
+		for _, wanted := range f.tags {
+			if eval.refNames(wanted)[strings.ToLower(tag)] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (f pageTagsFilter) pageLevel() bool { return true }
+
+// propertyFilter matches blocks, or pages with onPage, that have a property,
+// optionally with a given value. List values match if any item does.
+type propertyFilter struct {
+	name     string
+	value    string
+	hasValue bool
+	onPage   bool
+}
+
+func (f propertyFilter) match(_ *evaluation, page *graph.Page, block *graph.Block) bool {
+	if f.onPage {
+		block = page.Root
+	}
+
+	if block == nil {
+		return false
+	}
+
+	property, ok := block.Properties.Get(f.name)
+	if !ok {
+		return false
+	}
+
+	if !f.hasValue {
+		return true
+	}
+
+	for _, item := range property.Parsed().Items() {
+		if strings.ToLower(item.String()) == f.value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f propertyFilter) pageLevel() bool { return f.onPage }
+
+// taskFilter matches tasks with any of the given markers.
+type taskFilter struct{ markers []string }
+
+func (f taskFilter) match(_ *evaluation, _ *graph.Page, block *graph.Block) bool {
+	if block == nil || block.Task == nil {
+		return false
+	}
+
+	for _, marker := range f.markers {
+		if task := graph.ParseTask(marker, time.UTC); task != nil && task.Marker == block.Task.Marker {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f taskFilter) pageLevel() bool { return false }
+
+// priorityFilter matches tasks with any of the given priorities.
+type priorityFilter struct{ priorities []string }
+
+func (f priorityFilter) match(_ *evaluation, _ *graph.Page, block *graph.Block) bool {
+	if block == nil || block.Task == nil {
+		return false
+	}
+
+	for _, priority := range f.priorities {
+		if priority == block.Task.Priority {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f priorityFilter) pageLevel() bool { return false }
+
+// betweenFilter matches blocks on journal pages within a range of days.
+type betweenFilter struct {
+	start string
+	end   string
+}
+
+func (f betweenFilter) match(eval *evaluation, page *graph.Page, _ *graph.Block) bool {
+	day, ok := journalDay(page)
+	if !ok {
+		return false
+	}
+
+	start, ok := eval.resolveDay(f.start)
+	if !ok {
+		return false
+	}
+
+	end, ok := eval.resolveDay(f.end)
+	if !ok {
+		return false
+	}
+
+	return !day.Before(start) && !day.After(end)
+}
+
+func (f betweenFilter) pageLevel() bool { return false }
+
+var relativeDayRe = regexp.MustCompile(`^([+-]?)(\d+)([dwmy])$`)
+
+// resolveDay reads a between argument: today, yesterday, tomorrow, a relative offset
+// like -7d, a date like 20240603 or 2024-06-03, or the name of a journal page.
+func (e *evaluation) resolveDay(arg string) (time.Time, bool) {
+	today := time.Date(e.now.Year(), e.now.Month(), e.now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch strings.ToLower(arg) {
+	case "today", "now":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+
+	if match := relativeDayRe.FindStringSubmatch(arg); match != nil {
+		amount, _ := strconv.Atoi(match[2])
+		if match[1] == "-" {
+			amount = -amount
+		}
+
+		switch match[3] {
+		case "d":
+			return today.AddDate(0, 0, amount), true
+		case "w":
+			return today.AddDate(0, 0, 7*amount), true
+		case "m":
+			return today.AddDate(0, amount, 0), true
+		default:
+			return today.AddDate(amount, 0, 0), true
+		}
+	}
+
+	for _, layout := range []string{"20060102", "2006-01-02", "2006/01/02"} {
+		if day, err := time.Parse(layout, arg); err == nil {
+			return day, true
+		}
+	}
+
+	if page, err := e.graph.FindPage(arg); err == nil {
+		return journalDay(page)
+	}
+
+	return time.Time{}, false
+}
+
+// journalDay returns the date of a journal page.
+func journalDay(page *graph.Page) (time.Time, bool) {
+	if !page.IsJournal() {
+		return time.Time{}, false
+	}
+
+	name := page.JournalDay
+	if name == "" {
+		name = strings.ReplaceAll(page.Name, "/", "-")
+	}
+
+	day, err := time.Parse("2006-01-02", name)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return day, true
+}
@@ -0,0 +1,119 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/graph/graphtest"
+	"export-logseq/query"
+)
+
+func queryGraph(t *testing.T) *graph.Graph {
+	t.Helper()
+
+	g := graph.NewGraph()
+
+	graphtest.AddPage(t, &g, "Project X", map[string]string{"tags": "project", "status": "active"},
+		"TODO [#A] Write the plan",
+		"DONE Kick off [[Project Y]]",
+		"Notes about [[Meetings]]",
+		"  follow up with the team",
+	)
+	graphtest.AddPage(t, &g, "Project Y", map[string]string{"tags": "project, archived", "status": "done"},
+		"LATER [#B] Close out",
+		"Dune\ntype:: book",
+	)
+	graphtest.AddPage(t, &g, "2024-06-01", nil, "DOING review #Meetings")
+	graphtest.AddPage(t, &g, "2024-06-10", nil, "TODO plan the week")
+	graphtest.AddPage(t, &g, "Dashboard", nil, "{{query (task TODO)}}")
+
+	g.Pages["2024-06-01"].JournalDay = "2024-06-01"
+	g.Pages["2024-06-10"].JournalDay = "2024-06-10"
+
+	return &g
+}
+
+func runQuery(t *testing.T, text string) query.Result {
+	t.Helper()
+
+	q, err := query.Parse(text)
+	require.NoError(t, err)
+
+	return q.Run(queryGraph(t), time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC))
+}
+
+func blockContents(result query.Result) []string {
+	contents := []string{}
+
+	for _, block := range result.Blocks {
+		contents = append(contents, block.Content.Markdown)
+	}
+
+	return contents
+}
+
+func pageNames(result query.Result) []string {
+	names := []string{}
+
+	for _, page := range result.Pages {
+		names = append(names, page.Name)
+	}
+
+	return names
+}
+
+func TestQuery_Blocks(t *testing.T) {
+	queryTests := []struct {
+		query string
+		want  []string
+	}{
+		{"(task TODO)", []string{"TODO plan the week", "TODO [#A] Write the plan"}},
+		{"(task todo doing)", []string{"DOING review #Meetings", "TODO plan the week", "TODO [#A] Write the plan"}},
+		{"(priority A B)", []string{"TODO [#A] Write the plan", "LATER [#B] Close out"}},
+		{`"PLAN"`, []string{"TODO plan the week", "TODO [#A] Write the plan"}},
+		{"[[meetings]]", []string{"DOING review #Meetings", "Notes about [[Meetings]]", "follow up with the team"}},
+		{`(and (page "Project X") (not (task DONE TODO)))`, []string{"Notes about [[Meetings]]", "follow up with the team"}},
+		{"(or [[Project Y]] (priority B))", []string{"DONE Kick off [[Project Y]]", "LATER [#B] Close out"}},
+		{"(between -7d today)", []string{"TODO plan the week"}},
+		{"(between 20240601 [[2024-06-05]])", []string{"DOING review #Meetings"}},
+		{"(property type book)", []string{"Dune"}},
+		{"(task TODO) (page-tags project)", []string{"TODO [#A] Write the plan"}},
+	}
+
+	for _, tt := range queryTests {
+		result := runQuery(t, tt.query)
+
+		assert.Empty(t, result.Pages, tt.query)
+		assert.Equal(t, tt.want, blockContents(result), tt.query)
+	}
+}
+
+func TestQuery_Pages(t *testing.T) {
+	queryTests := []struct {
+		query string
+		want  []string
+	}{
+		{"(page-tags project)", []string{"Project X", "Project Y"}},
+		{"(and (page-tags [[project]]) (not (page-tags archived)))", []string{"Project X"}},
+		{"(page-property status done)", []string{"Project Y"}},
+	}
+
+	for _, tt := range queryTests {
+		result := runQuery(t, tt.query)
+
+		assert.Empty(t, result.Blocks, tt.query)
+		assert.Equal(t, tt.want, pageNames(result), tt.query)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, text := range []string{"", "(task TODO", "(unknown x)", `"open`, "(between today)", "()"} {
+		_, err := query.Parse(text)
+
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"olympos.io/encoding/edn"
+
+	"export-logseq/graph"
+)
+
+// sortTransform is a :result-transform that sorts results by an attribute, like
+// (fn [result] (sort-by (fn [h] (get h :block/priority "Z")) result)).
+// A property sort reads (get-in h [:block/properties :rating]).
+type sortTransform struct {
+	path       []edn.Keyword
+	fallback   any
+	descending bool
+}
+
+// parseSortTransform reads the sort-by forms of :result-transform. Other transforms aren't supported.
+func parseSortTransform(form any) (*sortTransform, error) {
+	items, ok := form.([]any)
+	if !ok || len(items) != 3 || items[0] != edn.Symbol("fn") {
+		return nil, errors.New("only (fn [result] (sort-by ...)) is supported")
+	}
+
+	return parseSortBody(items[2])
+}
+
+func parseSortBody(form any) (*sortTransform, error) {
+	items, ok := form.([]any)
+	if !ok || len(items) == 0 {
+		return nil, errors.Errorf("unsupported transform %v", form)
+	}
+
+	switch items[0] {
+	case edn.Symbol("reverse"):
+		if len(items) != 2 {
+			return nil, errors.New("reverse takes one argument")
+		}
+
+		transform, err := parseSortBody(items[1])
+		if err != nil {
+			return nil, err
+		}
+
+		transform.descending = !transform.descending
+
+		return transform, nil
+	case edn.Symbol("sort-by"):
+		if len(items) != 3 && len(items) != 4 {
+			return nil, errors.New("sort-by takes a key, an optional comparator, and the results")
+		}
+
+		transform, err := parseSortKey(items[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if len(items) == 4 {
+			switch items[2] {
+			case edn.Symbol(">"):
+				transform.descending = true
+			case edn.Symbol("<"):
+			default:
+				return nil, errors.Errorf("unsupported comparator %v", items[2])
+			}
+		}
+
+		return transform, nil
+	default:
+		return nil, errors.Errorf("unsupported transform %v", items[0])
+	}
+}
+
+// parseSortKey reads a sort-by key: an attribute keyword, or a function that gets one.
+func parseSortKey(form any) (*sortTransform, error) {
+	if keyword, ok := form.(edn.Keyword); ok {
+		return &sortTransform{path: []edn.Keyword{keyword}}, nil
+	}
+
+	fn, ok := form.([]any)
+	if !ok || len(fn) != 3 || fn[0] != edn.Symbol("fn") {
+		return nil, errors.Errorf("unsupported sort key %v", form)
+	}
+
+	get, ok := fn[2].([]any)
+	if !ok || len(get) < 3 || len(get) > 4 {
+		return nil, errors.Errorf("unsupported sort key %v", form)
+	}
+
+	transform := sortTransform{}
+	if len(get) == 4 {
+		transform.fallback = get[3]
+	}
+
+	switch get[0] {
+	case edn.Symbol("get"):
+		if keyword, ok := get[2].(edn.Keyword); ok {
+			transform.path = []edn.Keyword{keyword}
+		}
+	case edn.Symbol("get-in"):
+		if keys, ok := get[2].([]any); ok {
+			for _, key := range keys {
+				if keyword, ok := key.(edn.Keyword); ok {
+					transform.path = append(transform.path, keyword)
+				}
+			}
+
+			if len(transform.path) != len(keys) {
+				transform.path = nil
+			}
+		}
+	}
+
+	if len(transform.path) == 0 || len(transform.path) > 2 ||
+		(len(transform.path) == 2 && transform.path[0] != "block/properties") {
+		return nil, errors.Errorf("unsupported sort key %v", form)
+	}
+
+	return &transform, nil
+}
+
+func (t *sortTransform) apply(eval *datalogEvaluation, result *Result) {
+	sort.SliceStable(result.Pages, func(i, j int) bool {
+		return t.less(t.key(eval, result.Pages[i]), t.key(eval, result.Pages[j]))
+	})
+
+	sort.SliceStable(result.Blocks, func(i, j int) bool {
+		return t.less(t.key(eval, result.Blocks[i]), t.key(eval, result.Blocks[j]))
+	})
+}
+
+// key returns the value an entity sorts by.
+func (t *sortTransform) key(eval *datalogEvaluation, entity any) any {
+	values := eval.attribute(entity, t.path[0])
+	if len(values) == 0 {
+		return t.fallback
+	}
+
+	if len(t.path) == 1 {
+		return values[0]
+	}
+
+	properties, ok := values[0].(*graph.PropertyMap)
+	if !ok {
+		return t.fallback
+	}
+
+	if value, ok := propertyValue(properties, string(t.path[1])); ok {
+		return value
+	}
+
+	return t.fallback
+}
+
+// less orders keys, putting missing or incomparable keys last.
+func (t *sortTransform) less(a any, b any) bool {
+	c, ok := compareValues(a, b)
+	if !ok {
+		return a != nil && b == nil
+	}
+
+	if t.descending {
+		return c > 0
+	}
+
+	return c < 0
+}
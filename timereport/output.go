@@ -0,0 +1,126 @@
+package timereport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Format selects how a report is written.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatCSV   Format = "csv"
+	FormatJSON  Format = "json"
+)
+
+// Write writes a report in the given format.
+func Write(w io.Writer, report Report, format Format) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, report)
+	case FormatJSON:
+		return WriteJSON(w, report)
+	default:
+		return WriteTable(w, report)
+	}
+}
+
+// WriteTable writes a report as an aligned text table with a total line.
+func WriteTable(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%s\tTIME\tENTRIES\n", strings.ToUpper(string(report.GroupBy)))
+
+	for _, row := range append(report.Rows, report.Total) {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", row.Group, formatClock(row.Duration), row.Entries)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return errors.Wrap(err, "writing table")
+	}
+
+	return nil
+}
+
+// WriteCSV writes report rows as CSV, with time in decimal hours.
+func WriteCSV(w io.Writer, report Report) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{{string(report.GroupBy), "hours", "entries"}}
+
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			row.Group,
+			strconv.FormatFloat(roundHours(row.Hours()), 'f', 2, 64),
+			strconv.Itoa(row.Entries),
+		})
+	}
+
+	if err := cw.WriteAll(records); err != nil {
+		return errors.Wrap(err, "writing CSV")
+	}
+
+	return nil
+}
+
+type jsonRow struct {
+	Group   string  `json:"group"`
+	Seconds int64   `json:"seconds"`
+	Hours   float64 `json:"hours"`
+	Entries int     `json:"entries"`
+}
+
+func newJSONRow(row Row) jsonRow {
+	return jsonRow{
+		Group:   row.Group,
+		Seconds: int64(row.Duration.Seconds()),
+		Hours:   roundHours(row.Hours()),
+		Entries: row.Entries,
+	}
+}
+
+// WriteJSON writes a report as a JSON object with its rows and total.
+func WriteJSON(w io.Writer, report Report) error {
+	jsonReport := struct {
+		GroupBy GroupBy   `json:"group_by"`
+		Rows    []jsonRow `json:"rows"`
+		Total   jsonRow   `json:"total"`
+	}{
+		GroupBy: report.GroupBy,
+		Rows:    []jsonRow{},
+		Total:   newJSONRow(report.Total),
+	}
+
+	for _, row := range report.Rows {
+		jsonReport.Rows = append(jsonReport.Rows, newJSONRow(row))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(jsonReport); err != nil {
+		return errors.Wrap(err, "writing JSON")
+	}
+
+	return nil
+}
+
+// formatClock writes a duration as hours and minutes, like 12:05.
+func formatClock(duration time.Duration) string {
+	minutes := int64(duration.Round(time.Minute).Minutes())
+
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
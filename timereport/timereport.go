@@ -0,0 +1,202 @@
+// Package timereport summarizes time logged in task LOGBOOK clock entries.
+package timereport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"export-logseq/graph"
+)
+
+// GroupBy selects how clock entries are combined in a report.
+type GroupBy string
+
+const (
+	GroupByPage      GroupBy = "page"
+	GroupByTag       GroupBy = "tag"
+	GroupByNamespace GroupBy = "namespace"
+	GroupByDay       GroupBy = "day"
+	GroupByWeek      GroupBy = "week"
+)
+
+// Group key for entries that have no tag or namespace.
+const noGroup = "(none)"
+
+// Entry is a finished clock interval on a task block.
+type Entry struct {
+	Page      string
+	Namespace string
+	Tags      []string
+	BlockID   string
+	Start     time.Time
+	End       time.Time
+}
+
+// Duration returns how long the entry's clock ran.
+func (e Entry) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Row is the total time for one group in a report.
+type Row struct {
+	Group    string
+	Duration time.Duration
+	Entries  int
+}
+
+// Report is logged time totalled by group.
+// Entries with several tags count toward each of their tags, but only once toward the total.
+type Report struct {
+	GroupBy GroupBy
+	Rows    []Row
+	Total   Row
+}
+
+// Hours returns the row's duration in hours.
+func (r Row) Hours() float64 {
+	return r.Duration.Hours()
+}
+
+// Collect gathers finished clock entries from every task in a graph that started
+// within [since, until). Zero times leave that end of the range open.
+func Collect(g *graph.Graph, since time.Time, until time.Time) []Entry {
+	entries := []Entry{}
+
+	for _, page := range g.Pages {
+		for _, block := range page.AllBlocks {
+			if block.Task == nil {
+				continue
+			}
+
+			tags := blockTags(page, block)
+
+			for _, clock := range block.Task.Clock {
+				if clock.End == nil {
+					continue
+				}
+
+				if (!since.IsZero() && clock.Start.Before(since)) || (!until.IsZero() && !clock.Start.Before(until)) {
+					continue
+				}
+
+				entries = append(entries, Entry{
+					Page:      page.Name,
+					Namespace: pageNamespace(page),
+					Tags:      tags,
+					BlockID:   block.ID,
+					Start:     clock.Start,
+					End:       *clock.End,
+				})
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+
+	return entries
+}
+
+// Summarize totals entries by group, ordering rows by group name.
+func Summarize(entries []Entry, groupBy GroupBy) Report {
+	rowsByGroup := map[string]*Row{}
+	total := Row{Group: "TOTAL"}
+
+	for _, entry := range entries {
+		total.Duration += entry.Duration()
+		total.Entries++
+
+		for _, group := range entryGroups(entry, groupBy) {
+			row, ok := rowsByGroup[group]
+			if !ok {
+				row = &Row{Group: group}
+				rowsByGroup[group] = row
+			}
+
+			row.Duration += entry.Duration()
+			row.Entries++
+		}
+	}
+
+	rows := []Row{}
+	for _, row := range rowsByGroup {
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Group < rows[j].Group
+	})
+
+	return Report{GroupBy: groupBy, Rows: rows, Total: total}
+}
+
+func entryGroups(entry Entry, groupBy GroupBy) []string {
+	switch groupBy {
+	case GroupByTag:
+		if len(entry.Tags) == 0 {
+			return []string{noGroup}
+		}
+
+		return entry.Tags
+	case GroupByNamespace:
+		if entry.Namespace == "" {
+			return []string{noGroup}
+		}
+
+		return []string{entry.Namespace}
+	case GroupByDay:
+		return []string{entry.Start.Format("2006-01-02")}
+	case GroupByWeek:
+		year, week := entry.Start.ISOWeek()
+
+		return []string{fmt.Sprintf("%d-W%02d", year, week)}
+	default:
+		return []string{entry.Page}
+	}
+}
+
+// blockTags lists the lowercased tags on a block, its page, and in its content.
+func blockTags(page *graph.Page, block *graph.Block) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+
+	addTag := func(tag string) {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, tag := range page.Tags() {
+		addTag(tag)
+	}
+
+	for _, link := range block.Links() {
+		if link.IsTag() {
+			addTag(link.LinkPath)
+		}
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// pageNamespace returns the namespace part of a page name, like "clients/acme" for
+// "clients/acme/Kickoff". Journal names never have a namespace.
+func pageNamespace(page *graph.Page) string {
+	if page.IsJournal() {
+		return ""
+	}
+
+	index := strings.LastIndex(page.Name, "/")
+	if index < 0 {
+		return ""
+	}
+
+	return page.Name[:index]
+}
@@ -0,0 +1,121 @@
+package timereport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"export-logseq/graph"
+	"export-logseq/graph/graphtest"
+	"export-logseq/timereport"
+)
+
+func reportGraph(t *testing.T) *graph.Graph {
+	t.Helper()
+
+	g := graph.NewGraph()
+
+	graphtest.AddPage(t, &g, "clients/Acme/Kickoff", nil, `DONE Kickoff meeting #billable
+:LOGBOOK:
+CLOCK: [2024-06-03 Mon 10:00:00]--[2024-06-03 Mon 11:30:00] =>  01:30:00
+CLOCK: [2024-06-10 Mon 09:00:00]--[2024-06-10 Mon 09:20:00] =>  00:20:00
+:END:`)
+	graphtest.AddPage(t, &g, "Chores", nil, `DOING Laundry
+:LOGBOOK:
+CLOCK: [2024-06-04 Tue 14:00:00]--[2024-06-04 Tue 14:45:00] =>  00:45:00
+CLOCK: [2024-06-05 Wed 08:00:00]
+:END:`)
+
+	return &g
+}
+
+func TestCollect(t *testing.T) {
+	entries := timereport.Collect(reportGraph(t), time.Time{}, time.Time{})
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, "clients/Acme/Kickoff", entries[0].Page)
+	assert.Equal(t, "clients/Acme", entries[0].Namespace)
+	assert.Equal(t, []string{"billable"}, entries[0].Tags)
+	assert.Equal(t, 90*time.Minute, entries[0].Duration())
+	assert.Equal(t, "Chores", entries[1].Page)
+	assert.Empty(t, entries[1].Tags)
+}
+
+func TestCollect_DateRange(t *testing.T) {
+	since := time.Date(2024, 6, 4, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local)
+	entries := timereport.Collect(reportGraph(t), since, until)
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Chores", entries[0].Page)
+}
+
+func TestSummarize(t *testing.T) {
+	entries := timereport.Collect(reportGraph(t), time.Time{}, time.Time{})
+
+	groupTests := []struct {
+		groupBy timereport.GroupBy
+		groups  []string
+	}{
+		{timereport.GroupByPage, []string{"Chores", "clients/Acme/Kickoff"}},
+		{timereport.GroupByTag, []string{"(none)", "billable"}},
+		{timereport.GroupByNamespace, []string{"(none)", "clients/Acme"}},
+		{timereport.GroupByDay, []string{"2024-06-03", "2024-06-04", "2024-06-10"}},
+		{timereport.GroupByWeek, []string{"2024-W23", "2024-W24"}},
+	}
+
+	for _, tt := range groupTests {
+		report := timereport.Summarize(entries, tt.groupBy)
+		groups := []string{}
+
+		for _, row := range report.Rows {
+			groups = append(groups, row.Group)
+		}
+
+		assert.Equal(t, tt.groups, groups, tt.groupBy)
+		assert.Equal(t, 155*time.Minute, report.Total.Duration, tt.groupBy)
+		assert.Equal(t, 3, report.Total.Entries, tt.groupBy)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	entries := timereport.Collect(reportGraph(t), time.Time{}, time.Time{})
+	report := timereport.Summarize(entries, timereport.GroupByPage)
+
+	var table bytes.Buffer
+	require.NoError(t, timereport.Write(&table, report, timereport.FormatTable))
+	assert.Equal(t, "PAGE                  TIME  ENTRIES\n"+
+		"Chores                0:45  1\n"+
+		"clients/Acme/Kickoff  1:50  2\n"+
+		"TOTAL                 2:35  3\n", table.String())
+
+	var csv bytes.Buffer
+	require.NoError(t, timereport.Write(&csv, report, timereport.FormatCSV))
+	assert.Equal(t, "page,hours,entries\nChores,0.75,1\nclients/Acme/Kickoff,1.83,2\n", csv.String())
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, timereport.Write(&jsonOut, report, timereport.FormatJSON))
+
+	var decoded struct {
+		GroupBy string `json:"group_by"`
+		Rows    []struct {
+			Group   string  `json:"group"`
+			Seconds int64   `json:"seconds"`
+			Hours   float64 `json:"hours"`
+		} `json:"rows"`
+		Total struct {
+			Seconds int64 `json:"seconds"`
+		} `json:"total"`
+	}
+
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	assert.Equal(t, "page", decoded.GroupBy)
+	require.Len(t, decoded.Rows, 2)
+	assert.Equal(t, int64(2700), decoded.Rows[0].Seconds)
+	assert.Equal(t, 1.83, decoded.Rows[1].Hours)
+	assert.Equal(t, int64(9300), decoded.Total.Seconds)
+}